@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"github.com/dev-mackan/gowiki/internal/apiserver"
+	"github.com/dev-mackan/gowiki/internal/auth"
 	"github.com/dev-mackan/gowiki/internal/db"
+	"github.com/dev-mackan/gowiki/internal/grpcserver"
+	"github.com/dev-mackan/gowiki/internal/namespaces"
 	"github.com/dev-mackan/gowiki/internal/repos"
 	"github.com/dev-mackan/gowiki/internal/reposervice"
 	_ "github.com/mattn/go-sqlite3"
@@ -10,6 +15,9 @@ import (
 )
 
 func main() {
+	reindex := flag.Bool("reindex", false, "Index the latest revision of every page again and exit, after upgrading the database")
+	flag.Parse()
+
 	dbConfig := db.DbConfig{
 		Addr:         "file:store.sqlite?cache=shared&journal_mode=WAL&busy_timeout=3000",
 		MaxOpenConns: 30,
@@ -21,7 +29,23 @@ func main() {
 		panic(err)
 	}
 	repo := repos.NewSqlRepository(db)
-	repoService := reposervice.NewRepoService(repo)
-	api := apiserver.NewAPIServer(":3000", repoService)
+	repoService := reposervice.NewRepoService(repo, namespaces.Default())
+	if *reindex {
+		indexed, err := repoService.ReindexPages(context.Background())
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Indexed %d pages", indexed)
+		return
+	}
+	// NOTE: Both servers share the repo service, so the gRPC change stream
+	// sees changes made through the HTTP API too, and the authenticator, so
+	// both accept the same tokens
+	authenticator := auth.DefaultAuthenticator()
+	api := apiserver.NewAPIServer(apiserver.DefaultAPIServerConfig(authenticator), repoService)
+	grpcServer := grpcserver.NewGRPCServer(grpcserver.DefaultGRPCServerConfig(authenticator), repoService)
+	go func() {
+		log.Fatal(grpcServer.Run())
+	}()
 	log.Fatal(api.Run())
 }
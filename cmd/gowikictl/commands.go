@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dev-mackan/gowiki/pkg/models"
+	"github.com/dev-mackan/gowiki/pkg/utils"
+)
+
+func (c *cli) list(ctx context.Context, args []string) error {
+	if err := expectArgs(args, 0, 0); err != nil {
+		return err
+	}
+	pages, err := c.api.GetPages(ctx)
+	if err != nil {
+		return err
+	}
+	if c.output == "json" {
+		return printJSON(pages)
+	}
+	tw := newTable("ID", "TITLE", "LATEST REV", "CREATED")
+	for _, p := range pages {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%s\n", p.PageId, p.Title, p.LatestRev, formatTime(p.CreatedAt))
+	}
+	return tw.Flush()
+}
+
+func (c *cli) get(ctx context.Context, args []string) error {
+	if err := expectArgs(args, 1, 2); err != nil {
+		return err
+	}
+	var bundle *models.PageBundle
+	var err error
+	if len(args) == 2 {
+		revId, perr := utils.ParseUintFromStr(args[1])
+		if perr != nil {
+			return perr
+		}
+		bundle, err = c.api.GetBundledPageWithRev(ctx, args[0], revId)
+	} else {
+		bundle, err = c.api.GetBundledPage(ctx, args[0])
+	}
+	if err != nil {
+		return err
+	}
+	if c.output == "json" {
+		return printJSON(bundle)
+	}
+	_, err = fmt.Print(bundle.Text.Content)
+	return err
+}
+
+func (c *cli) create(ctx context.Context, args []string) error {
+	if err := expectArgs(args, 1, 2); err != nil {
+		return err
+	}
+	var content []byte
+	var err error
+	if len(args) == 2 {
+		content, err = os.ReadFile(args[1])
+	} else {
+		content, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		return err
+	}
+	err = c.api.CreatePage(ctx, args[0], string(content))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "created %s\n", utils.SanitizeTitle(args[0]))
+	return nil
+}
+
+// Opens the latest revision in $EDITOR and publishes the result if it changed
+func (c *cli) edit(ctx context.Context, args []string) error {
+	if err := expectArgs(args, 1, 1); err != nil {
+		return err
+	}
+	bundle, err := c.api.GetBundledPage(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp("", "gowiki-*.md")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(bundle.Text.Content)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	editor := envOr("EDITOR", "vi")
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", file.Name())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("running %s: %w", editor, err)
+	}
+	content, err := os.ReadFile(file.Name())
+	if err != nil {
+		return err
+	}
+	if string(content) == bundle.Text.Content {
+		fmt.Fprintln(os.Stderr, "no changes")
+		return nil
+	}
+	err = c.api.UpdatePageContent(ctx, bundle.Page.PageId, string(content), "")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "updated %s\n", bundle.Page.Title)
+	return nil
+}
+
+func (c *cli) rename(ctx context.Context, args []string) error {
+	return c.renamePage(ctx, args, false)
+}
+
+// Renames a page together with its subpages
+func (c *cli) move(ctx context.Context, args []string) error {
+	return c.renamePage(ctx, args, true)
+}
+
+func (c *cli) renamePage(ctx context.Context, args []string, moveSubpages bool) error {
+	if err := expectArgs(args, 2, 2); err != nil {
+		return err
+	}
+	page, err := c.api.GetPage(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	err = c.api.UpdatePageTitle(ctx, page.PageId, args[1], moveSubpages)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "renamed %s to %s\n", page.Title, utils.SanitizeTitle(args[1]))
+	return nil
+}
+
+func (c *cli) delete(ctx context.Context, args []string) error {
+	if err := expectArgs(args, 1, 1); err != nil {
+		return err
+	}
+	page, err := c.api.GetPage(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	err = c.api.DeletePage(ctx, page.PageId)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "deleted %s\n", page.Title)
+	return nil
+}
+
+func (c *cli) history(ctx context.Context, args []string) error {
+	if err := expectArgs(args, 1, 1); err != nil {
+		return err
+	}
+	page, err := c.api.GetPage(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	revs, err := c.api.GetPageRevisions(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if c.output == "json" {
+		return printJSON(revs)
+	}
+	tw := newTable("REV", "TEXT", "CREATED", "")
+	for _, rev := range revs {
+		latest := ""
+		if rev.RevId == page.LatestRev {
+			latest = "latest"
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\n", rev.RevId, rev.TextId, formatTime(rev.CreatedAt), latest)
+	}
+	return tw.Flush()
+}
+
+// Diffs two revisions. With a single revision it is compared to the
+// revision before it on the same page.
+func (c *cli) diff(ctx context.Context, args []string) error {
+	if err := expectArgs(args, 1, 2); err != nil {
+		return err
+	}
+	var revIds []uint
+	for _, arg := range args {
+		revId, err := utils.ParseUintFromStr(arg)
+		if err != nil {
+			return err
+		}
+		revIds = append(revIds, revId)
+	}
+	if len(revIds) == 1 {
+		prev, err := c.previousRev(ctx, revIds[0])
+		if err != nil {
+			return err
+		}
+		revIds = []uint{prev, revIds[0]}
+	}
+
+	var texts [2]string
+	for i, revId := range revIds {
+		if revId == 0 {
+			continue
+		}
+		text, err := c.api.GetRawText(ctx, revId)
+		if err != nil {
+			return err
+		}
+		texts[i] = text.Content
+	}
+	lines := diffLines(splitLines(texts[0]), splitLines(texts[1]))
+	if c.output == "json" {
+		return printJSON(lines)
+	}
+	fmt.Printf("--- rev %d\n+++ rev %d\n", revIds[0], revIds[1])
+	for _, l := range lines {
+		fmt.Printf("%s%s\n", l.Op, l.Text)
+	}
+	return nil
+}
+
+// Returns the revision before revId on the same page, or 0 if it is the first
+func (c *cli) previousRev(ctx context.Context, revId uint) (uint, error) {
+	bundle, err := c.api.GetBundledRevision(ctx, revId)
+	if err != nil {
+		return 0, err
+	}
+	revs, err := c.api.GetPageRevisions(ctx, bundle.Page.Title)
+	if err != nil {
+		return 0, err
+	}
+	var prev uint
+	for _, rev := range revs {
+		if rev.RevId < revId && rev.RevId > prev {
+			prev = rev.RevId
+		}
+	}
+	return prev, nil
+}
+
+func (c *cli) revert(ctx context.Context, args []string) error {
+	if err := expectArgs(args, 2, 2); err != nil {
+		return err
+	}
+	revId, err := utils.ParseUintFromStr(args[1])
+	if err != nil {
+		return err
+	}
+	bundle, err := c.api.GetBundledPageWithRev(ctx, args[0], revId)
+	if err != nil {
+		return err
+	}
+	if bundle.Revision.PageId != bundle.Page.PageId {
+		return fmt.Errorf("revision %d does not belong to %s", revId, bundle.Page.Title)
+	}
+	err = c.api.UpdatePageContent(ctx, bundle.Page.PageId, bundle.Text.Content, "")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "reverted %s to revision %d\n", bundle.Page.Title, revId)
+	return nil
+}
+
+func expectArgs(args []string, min int, max int) error {
+	if len(args) < min || len(args) > max {
+		if min == max {
+			return fmt.Errorf("expected %d arguments, got %d", min, len(args))
+		}
+		return fmt.Errorf("expected %d to %d arguments, got %d", min, max, len(args))
+	}
+	return nil
+}
+
+func printJSON(v any) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := os.Stdout.Write(buf.Bytes())
+	return err
+}
+
+func newTable(columns ...string) *tabwriter.Writer {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(columns, "\t"))
+	return tw
+}
+
+func formatTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
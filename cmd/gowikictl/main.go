@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dev-mackan/gowiki/pkg/client"
+)
+
+const usage = `Usage: gowikictl [flags] <command> [args]
+
+Commands:
+  list                        List all pages
+  get <title> [rev_id]        Print the content of a page
+  create <title> [file]       Create a page from a file, or stdin
+  edit <title>                Edit a page in $EDITOR
+  rename <title> <new_title>  Rename a page
+  move <title> <new_title>    Rename a page and its subpages
+  delete <title>              Delete a page
+  history <title>             List the revisions of a page
+  diff <rev_id> [rev_id]      Diff two revisions, or a revision against its parent
+  revert <title> <rev_id>     Publish the content of an old revision as a new one
+
+Flags:
+`
+
+type cli struct {
+	api    *client.Client
+	output string
+}
+
+func main() {
+	flags := flag.NewFlagSet("gowikictl", flag.ExitOnError)
+	addr := flags.String("addr", envOr("GOWIKI_API_ADDR", "http://localhost:3000/api/v1"), "API address, or $GOWIKI_API_ADDR")
+	token := flags.String("token", os.Getenv("GOWIKI_API_TOKEN"), "API token, or $GOWIKI_API_TOKEN")
+	output := flags.String("o", "table", "Output format: table or json")
+	timeout := flags.Duration("timeout", 30*time.Second, "Request timeout")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	flags.Parse(os.Args[1:])
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if *output != "table" && *output != "json" {
+		fatalf("unknown output format %q", *output)
+	}
+
+	api := client.NewClient(*addr, &http.Client{Timeout: *timeout})
+	api.SetToken(*token)
+	c := &cli{api: api, output: *output}
+
+	commands := map[string]func(context.Context, []string) error{
+		"list":    c.list,
+		"get":     c.get,
+		"create":  c.create,
+		"edit":    c.edit,
+		"rename":  c.rename,
+		"move":    c.move,
+		"delete":  c.delete,
+		"history": c.history,
+		"diff":    c.diff,
+		"revert":  c.revert,
+	}
+	name := flags.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := cmd(context.Background(), flags.Args()[1:]); err != nil {
+		fatalf("%s: %v", name, err)
+	}
+}
+
+func envOr(key string, def string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return def
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "gowikictl: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -2,9 +2,15 @@ package apiserver
 
 import (
 	"encoding/json"
-	"github.com/dev-mackan/gowiki/internal/messages"
+	"fmt"
+	"github.com/dev-mackan/gowiki/internal/graphqlapi"
+	"github.com/dev-mackan/gowiki/internal/markdown"
 	"github.com/dev-mackan/gowiki/internal/middleware"
 	"github.com/dev-mackan/gowiki/internal/reposervice"
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+	"github.com/dev-mackan/gowiki/pkg/messages"
+	"github.com/dev-mackan/gowiki/pkg/models"
+	graphql "github.com/graph-gophers/graphql-go"
 	"log"
 	"net/http"
 )
@@ -12,47 +18,85 @@ import (
 type APIServer struct {
 	listenAddr string
 	repo       *reposervice.RepoService
+	rateLimit  func(http.Handler) http.Handler
+	auth       func(http.Handler) http.Handler
+	idempotent func(http.Handler) http.Handler
+	markdown   *markdown.Renderer
+	graphql    *graphql.Schema
 }
 
-func NewAPIServer(listenAddr string, repo *reposervice.RepoService) *APIServer {
+func NewAPIServer(config *APIServerConfig, repo *reposervice.RepoService) *APIServer {
 	return &APIServer{
-		listenAddr,
+		config.listenAddr,
 		repo,
+		middleware.NewRateLimitMiddleware(config.rateLimit),
+		middleware.NewAuthMiddleware(config.auth),
+		middleware.NewIdempotencyMiddleware(repo, config.idempotencyWindow),
+		markdown.NewRenderer(config.markdown),
+		graphqlapi.NewSchema(repo),
 	}
 }
 
-func (s *APIServer) mount() *http.ServeMux {
+// Returns the router and the patterns of all routes mounted on it
+func (s *APIServer) mount() (*http.ServeMux, []string) {
 	router := http.NewServeMux()
 	logger := middleware.NewLoggerMiddleware("API-SERVER")
+	var routes []string
+	handle := func(pattern string, f apiFunc) {
+		router.Handle(pattern, logger(makeApiHandlerFunc(f)))
+		routes = append(routes, pattern)
+	}
+	s.mountV1(func(pattern string, f apiFunc) {
+		handle(pattern, deprecated(f))
+	})
+	s.mountV2(handle)
+	handle("POST /api/graphql", s.graphQL)
+	// NOTE: Added after v1 was deprecated, so not marked as such
+	handle("POST /api/v1/batch", s.runBatch)
+	handle("GET /api/v1/openapi.json", s.getOpenAPI)
+	return router, routes
+}
+
+// v1 is deprecated in favour of v2, see mountV2
+func (s *APIServer) mountV1(handle func(string, apiFunc)) {
 	// POST
-	router.Handle("POST /api/v1/bundled/new", logger(makeApiHandlerFunc(s.createBundledPage)))
+	handle("POST /api/v1/bundled/new", s.createBundledPage)
+	handle("POST /api/v1/render", s.renderMarkdown)
+	handle("POST /api/v1/expand", s.expandTemplates)
 	// PUT
-	router.Handle("PUT /api/v1/pages/{page_id}/update/title", logger(makeApiHandlerFunc(s.updatePageTitle)))
-	router.Handle("PUT /api/v1/pages/{page_id}/update/content", logger(makeApiHandlerFunc(s.updatePageContent)))
+	handle("PUT /api/v1/pages/{page_id}/update/title", s.updatePageTitle)
+	handle("PUT /api/v1/pages/{page_id}/update/content", s.updatePageContent)
+	handle("PUT /api/v1/pages/{page_id}/draft", s.saveDraft)
+	handle("PUT /api/v1/pages/{page_id}/sections/{section...}", s.updateSection)
 	// DELETE
 	//TODO: Add page id to url
-	router.Handle("DELETE /api/v1/bundled/delete", logger(makeApiHandlerFunc(s.deleteBundledPage)))
+	handle("DELETE /api/v1/bundled/delete", s.deleteBundledPage)
+	handle("DELETE /api/v1/pages/{page_id}/draft", s.deleteDraft)
 	// GET
-	router.Handle("GET /api/v1/pages", logger(makeApiHandlerFunc(s.getPages)))
-	router.Handle("GET /api/v1/pages/{page_title}", logger(makeApiHandlerFunc(s.getPage)))
-	router.Handle("GET /api/v1/bundled/{page_title}", logger(makeApiHandlerFunc(s.getBundledPage)))
-	router.Handle("GET /api/v1/pages/{page_title}/revisions", logger(makeApiHandlerFunc(s.getPageRevs)))
-	router.Handle("GET /api/v1/bundled/{page_title}/revisions/{rev_id}", logger(makeApiHandlerFunc(s.getBundledPageWithRev)))
-	router.Handle("GET /api/v1/revisions/{rev_id}/text/raw", logger(makeApiHandlerFunc(s.getRawTextForPageWithRev)))
-	return router
+	handle("GET /api/v1/pages", s.getPages)
+	handle("GET /api/v1/pages/{page_title}", s.getPage)
+	handle("GET /api/v1/bundled/{page_title}", s.getBundledPage)
+	handle("GET /api/v1/pages/{page_title}/revisions", s.getPageRevs)
+	handle("GET /api/v1/bundled/{page_title}/revisions/{rev_id}", s.getBundledPageWithRev)
+	handle("GET /api/v1/revisions/{rev_id}", s.getBundledRevision)
+	handle("GET /api/v1/revisions/{rev_id}/text/raw", s.getRawTextForPageWithRev)
+	handle("GET /api/v1/pages/{page_id}/draft", s.getDraft)
+	handle("GET /api/v1/pages/{page_id}/children", s.getChildren)
+	handle("GET /api/v1/pages/{page_id}/used-by", s.getTemplateUsers)
+	handle("GET /api/v1/pages/{page_id}/sections/{section...}", s.getSection)
 }
 
 func (s *APIServer) Run() error {
-	mux := s.mount()
+	mux, _ := s.mount()
 	log.Println("GOWIKI-API listening on: ", s.listenAddr)
-	return http.ListenAndServe(s.listenAddr, mux)
+	return http.ListenAndServe(s.listenAddr, s.rateLimit(s.auth(s.idempotent(mux))))
 }
 
 func encodeJSON[T any](w http.ResponseWriter, r *http.Request, status int, v T) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(v); err != nil {
-		return ReplyErr(err)
+		return InternalServerErr(err)
 	}
 	return nil
 }
@@ -60,7 +104,7 @@ func encodeJSON[T any](w http.ResponseWriter, r *http.Request, status int, v T)
 func decodeJSON[T any](r *http.Request) (T, error) {
 	var v T
 	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
-		return v, ReceiveErr(err)
+		return v, fmt.Errorf("invalid request body: %w", err)
 	}
 	return v, nil
 }
@@ -71,8 +115,7 @@ func makeApiHandlerFunc(f apiFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if err := f(w, r); err != nil {
 			log.Println(err)
-			status := errToStatusCode(err)
-			encodeJSON(w, r, status, err)
+			writeProblem(w, r, err)
 		}
 	}
 }
@@ -84,6 +127,9 @@ func (s *APIServer) getPage(w http.ResponseWriter, r *http.Request) error {
 	if err != nil {
 		return parseDbErr(err)
 	}
+	if notModified(w, r, pageETag(page)) {
+		return nil
+	}
 	return encodeJSON(w, r, 200, page)
 }
 
@@ -94,6 +140,9 @@ func (s *APIServer) getBundledPage(w http.ResponseWriter, r *http.Request) error
 	if err != nil {
 		return parseDbErr(err)
 	}
+	if notModified(w, r, pageETag(bundle.Page)) {
+		return nil
+	}
 	return encodeJSON(w, r, 200, bundle)
 }
 
@@ -103,7 +152,7 @@ func (s *APIServer) createBundledPage(w http.ResponseWriter, r *http.Request) er
 	if err != nil {
 		return BadRequestErr(err)
 	}
-	err = s.repo.CreateBundledPage(ctx, br.PageTitle, br.TextContent)
+	_, err = s.repo.CreateBundledPage(ctx, br.PageTitle, br.TextContent)
 	if err != nil {
 		return parseDbErr(err)
 	}
@@ -131,7 +180,7 @@ func (s *APIServer) updatePageBundled(w http.ResponseWriter, r *http.Request) er
 	}
 	if br.TextContent == "" {
 	} else {
-		err = s.repo.UpdateBundledPage(ctx, br.PageId, br.PageTitle, br.TextContent)
+		err = s.repo.UpdateBundledPage(ctx, br.PageId, br.PageTitle, br.TextContent, false)
 		if err != nil {
 			return parseDbErr(err)
 		}
@@ -147,9 +196,9 @@ func (s *APIServer) updatePageTitle(w http.ResponseWriter, r *http.Request) erro
 		return BadRequestErr(err)
 	}
 	if rq.PageTitle == "" {
-		return BadRequestErr(err)
+		return wikierr.New(wikierr.ValidationFailed, "Provide a title")
 	}
-	err = s.repo.UpdatePageTitle(ctx, rq.PageId, rq.PageTitle)
+	err = s.repo.UpdatePageTitle(ctx, rq.PageId, rq.PageTitle, rq.MoveSubpages)
 	if err != nil {
 		return parseDbErr(err)
 	}
@@ -167,12 +216,29 @@ func (s *APIServer) updatePageContent(w http.ResponseWriter, r *http.Request) er
 	if err != nil {
 		return parseDbErr(err)
 	}
+	s.removePublishedDrafts(r, rq.PageId)
 	m := messages.Empty{}
 	return encodeJSON(w, r, 200, m)
 }
 
+// Lists all pages, or with ?property=owner:alice the pages with every
+// property given, or with ?title=Home the pages with the titles that exist
 func (s *APIServer) getPages(w http.ResponseWriter, r *http.Request) error {
 	ctx := r.Context()
+	if titles := r.URL.Query()["title"]; len(titles) > 0 {
+		pages, err := s.repo.FindPagesByTitles(ctx, titles)
+		if err != nil {
+			return parseDbErr(err)
+		}
+		return encodeJSON(w, r, 200, pages)
+	}
+	if props := r.URL.Query()["property"]; len(props) > 0 {
+		pages, err := s.repo.GetPagesByProperties(ctx, props, nil)
+		if err != nil {
+			return parseDbErr(err)
+		}
+		return encodeJSON(w, r, 200, pages)
+	}
 	pages, err := s.repo.GetPages(ctx)
 	if err != nil {
 		return parseDbErr(err)
@@ -180,6 +246,63 @@ func (s *APIServer) getPages(w http.ResponseWriter, r *http.Request) error {
 	return encodeJSON(w, r, 200, pages)
 }
 
+// Lists the subpages directly below the page, or all of them with ?recursive=true
+func (s *APIServer) getChildren(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	pages, err := s.repo.GetSubpages(r.Context(), pageId, r.URL.Query().Get("recursive") == "true")
+	if err != nil {
+		return parseDbErr(err)
+	}
+	return encodeJSON(w, r, 200, pages)
+}
+
+// Lists the pages using the page as a template
+func (s *APIServer) getTemplateUsers(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	pages, err := s.repo.GetTemplateUsers(r.Context(), pageId)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	return encodeJSON(w, r, 200, pages)
+}
+
+// Returns a section of the latest revision by index or heading path,
+// e.g. /sections/2 or /sections/Install/Linux
+func (s *APIServer) getSection(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	section, err := s.repo.GetSection(r.Context(), pageId, r.PathValue("section"))
+	if err != nil {
+		return parseDbErr(err)
+	}
+	return encodeJSON(w, r, 200, section)
+}
+
+func (s *APIServer) updateSection(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	rq, err := decodeJSON[messages.UpdateSectionRequest](r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	err = s.repo.UpdateSection(r.Context(), pageId, r.PathValue("section"), rq.TextContent, rq.BaseRev)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	s.removePublishedDrafts(r, pageId)
+	return encodeJSON(w, r, 200, messages.Empty{})
+}
+
 func (s *APIServer) getBundledPageWithRev(w http.ResponseWriter, r *http.Request) error {
 	title := r.PathValue("page_title")
 	revId, err := parseUintParam(r, "rev_id")
@@ -191,6 +314,25 @@ func (s *APIServer) getBundledPageWithRev(w http.ResponseWriter, r *http.Request
 	if err != nil {
 		return parseDbErr(err)
 	}
+	// NOTE: The revision is immutable, but the page in the bundle is not
+	if notModified(w, r, pageRevisionETag(bundle.Page, revId)) {
+		return nil
+	}
+	return encodeJSON(w, r, 200, bundle)
+}
+
+func (s *APIServer) getBundledRevision(w http.ResponseWriter, r *http.Request) error {
+	revId, err := parseUintParam(r, "rev_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	bundle, err := s.repo.GetBundledRevision(r.Context(), revId)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	if notModified(w, r, pageRevisionETag(bundle.Page, revId)) {
+		return nil
+	}
 	return encodeJSON(w, r, 200, bundle)
 }
 
@@ -199,11 +341,16 @@ func (s *APIServer) getRawTextForPageWithRev(w http.ResponseWriter, r *http.Requ
 	if err != nil {
 		return BadRequestErr(err)
 	}
-	ctx := r.Context()
-	text, err := s.repo.GetTextByRevID(ctx, revId)
+	etag := revisionETag(revId)
+	// NOTE: Revisions never change, so a matching ETag needs no lookup
+	if immutableNotModified(w, r, etag) {
+		return nil
+	}
+	text, err := s.repo.GetTextByRevID(r.Context(), revId)
 	if err != nil {
 		return parseDbErr(err)
 	}
+	immutable(w, r, etag)
 	return encodeJSON(w, r, 200, text)
 }
 
@@ -217,6 +364,113 @@ func (s *APIServer) getPageRevs(w http.ResponseWriter, r *http.Request) error {
 	return encodeJSON(w, r, 200, revs)
 }
 
+func (s *APIServer) renderMarkdown(w http.ResponseWriter, r *http.Request) error {
+	rq, err := decodeJSON[messages.RenderRequest](r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	expansion, err := s.repo.ExpandTemplates(r.Context(), rq.TextContent)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	doc, err := s.markdown.Render([]byte(expansion.TextContent))
+	if err != nil {
+		return InternalServerErr(err)
+	}
+	return encodeJSON(w, r, 200, &messages.RenderReply{Html: string(doc.HTML), Toc: doc.Headings})
+}
+
+// Returns the content with its template calls expanded, for clients that
+// render markdown themselves
+func (s *APIServer) expandTemplates(w http.ResponseWriter, r *http.Request) error {
+	rq, err := decodeJSON[messages.RenderRequest](r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	expansion, err := s.repo.ExpandTemplates(r.Context(), rq.TextContent)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	return encodeJSON(w, r, 200, expansion)
+}
+
+// Removes the drafts of the requests draft owner once a revision is published
+func (s *APIServer) removePublishedDrafts(r *http.Request, pageId uint) {
+	owner := r.Header.Get(messages.DraftOwnerHeader)
+	if owner == "" {
+		return
+	}
+	// NOTE: The drafts are published, failing to remove them is not fatal
+	if err := s.repo.DeleteDrafts(r.Context(), owner, pageId); err != nil {
+		log.Println(err)
+	}
+}
+
+func (s *APIServer) getDraft(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	owner, err := parseDraftOwner(r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	baseRev, err := parseOptionalUintQuery(r, "base_rev")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	ctx := r.Context()
+	draft, err := s.repo.GetDraft(ctx, owner, pageId, baseRev)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	return encodeJSON(w, r, 200, draft)
+}
+
+func (s *APIServer) saveDraft(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	owner, err := parseDraftOwner(r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	rq, err := decodeJSON[messages.SaveDraftRequest](r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	draft := models.Draft{
+		Owner:   owner,
+		PageId:  pageId,
+		BaseRev: rq.BaseRev,
+		Content: rq.TextContent,
+	}
+	ctx := r.Context()
+	err = s.repo.SaveDraft(ctx, &draft)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	return encodeJSON(w, r, 200, &messages.Empty{})
+}
+
+func (s *APIServer) deleteDraft(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	owner, err := parseDraftOwner(r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	ctx := r.Context()
+	err = s.repo.DeleteDrafts(ctx, owner, pageId)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	return encodeJSON(w, r, 200, &messages.Empty{})
+}
+
 func (s *APIServer) testHandler(w http.ResponseWriter, r *http.Request) error {
 	return encodeJSON(w, r, 200, "HELLO")
 }
@@ -0,0 +1,429 @@
+package apiserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+	"github.com/dev-mackan/gowiki/pkg/messages"
+	"github.com/dev-mackan/gowiki/pkg/models"
+)
+
+// Resource oriented routes. Pages are addressed by id, titles are
+// only used to look pages up. Successful replies are wrapped in a
+// messages.Envelope and errors are problem details as in v1.
+func (s *APIServer) mountV2(handle func(string, apiFunc)) {
+	handle("GET /api/v2/namespaces", s.listNamespacesV2)
+	handle("GET /api/v2/pages", s.listPagesV2)
+	handle("POST /api/v2/pages", s.createPageV2)
+	handle("GET /api/v2/pages/{page_id}", s.getPageV2)
+	handle("PATCH /api/v2/pages/{page_id}", s.patchPageV2)
+	handle("DELETE /api/v2/pages/{page_id}", s.deletePageV2)
+	handle("GET /api/v2/pages/{page_id}/children", s.listChildrenV2)
+	handle("GET /api/v2/pages/{page_id}/used-by", s.listTemplateUsersV2)
+	handle("GET /api/v2/pages/{page_id}/toc", s.getTocV2)
+	handle("GET /api/v2/pages/{page_id}/sections", s.listSectionsV2)
+	handle("GET /api/v2/pages/{page_id}/sections/{section...}", s.getSectionV2)
+	handle("PUT /api/v2/pages/{page_id}/sections/{section...}", s.updateSectionV2)
+	handle("GET /api/v2/pages/{page_id}/revisions", s.listRevisionsV2)
+	handle("POST /api/v2/pages/{page_id}/revisions", s.createRevisionV2)
+	handle("GET /api/v2/pages/{page_id}/revisions/{rev_id}", s.getRevisionV2)
+	handle("GET /api/v2/pages/{page_id}/draft", s.getDraftV2)
+	handle("PUT /api/v2/pages/{page_id}/draft", s.saveDraftV2)
+	handle("DELETE /api/v2/pages/{page_id}/draft", s.deleteDraftV2)
+	handle("POST /api/v2/render", s.renderMarkdownV2)
+	handle("POST /api/v2/expand", s.expandTemplatesV2)
+	handle("GET /api/v2/openapi.json", s.getOpenAPI)
+}
+
+func encodeData[T any](w http.ResponseWriter, r *http.Request, status int, v T) error {
+	return encodeJSON(w, r, status, messages.Envelope[T]{Data: v})
+}
+
+// Sets the deprecation headers on every v1 reply
+func deprecated(f apiFunc) apiFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", `</api/v2>; rel="successor-version"`)
+		return f(w, r)
+	}
+}
+
+func (s *APIServer) listNamespacesV2(w http.ResponseWriter, r *http.Request) error {
+	return encodeData(w, r, 200, s.repo.GetNamespaces())
+}
+
+// Lists all pages, the pages of a namespace with ?namespace=, or looks a
+// page up with ?title=. An empty namespace is the main namespace.
+// ?property=owner:alice, repeated for more, lists the pages with every
+// property, within the namespace if one is given.
+func (s *APIServer) listPagesV2(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	query := r.URL.Query()
+	if title := query.Get("title"); title != "" {
+		page, err := s.repo.GetPageByTitle(ctx, title)
+		if wikierr.CodeOf(err) == wikierr.PageNotFound {
+			return encodeData(w, r, 200, []*models.Page{})
+		}
+		if err != nil {
+			return parseDbErr(err)
+		}
+		return encodeData(w, r, 200, []*models.Page{page})
+	}
+	if props := query["property"]; len(props) > 0 {
+		var namespace *string
+		if query.Has("namespace") {
+			ns := query.Get("namespace")
+			namespace = &ns
+		}
+		pages, err := s.repo.GetPagesByProperties(ctx, props, namespace)
+		if err != nil {
+			return parseDbErr(err)
+		}
+		return encodeData(w, r, 200, *pages)
+	}
+	if query.Has("namespace") {
+		pages, err := s.repo.GetPagesInNamespace(ctx, query.Get("namespace"))
+		if err != nil {
+			return parseDbErr(err)
+		}
+		return encodeData(w, r, 200, *pages)
+	}
+	pages, err := s.repo.GetPages(ctx)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	return encodeData(w, r, 200, *pages)
+}
+
+func (s *APIServer) createPageV2(w http.ResponseWriter, r *http.Request) error {
+	rq, err := decodeJSON[messages.CreatePageRequest](r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	ctx := r.Context()
+	pageId, err := s.repo.CreateBundledPage(ctx, rq.Title, rq.TextContent)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	bundle, err := s.repo.GetBundledPageByID(ctx, pageId)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	w.Header().Set("Location", fmt.Sprintf("/api/v2/pages/%d", pageId))
+	return encodeData(w, r, 201, bundle)
+}
+
+// Returns the page with its latest revision and text
+func (s *APIServer) getPageV2(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	bundle, err := s.repo.GetBundledPageByID(r.Context(), pageId)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	if notModified(w, r, pageETag(bundle.Page)) {
+		return nil
+	}
+	return encodeData(w, r, 200, bundle)
+}
+
+// Renames the page and/or publishes new content
+func (s *APIServer) patchPageV2(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	rq, err := decodeJSON[messages.PatchPageRequest](r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	ctx := r.Context()
+	switch {
+	case rq.Title != nil && rq.TextContent != nil:
+		err = s.repo.UpdateBundledPage(ctx, pageId, *rq.Title, *rq.TextContent, rq.MoveSubpages)
+	case rq.Title != nil:
+		err = s.repo.UpdatePageTitle(ctx, pageId, *rq.Title, rq.MoveSubpages)
+	case rq.TextContent != nil:
+		err = s.repo.NewPageRev(ctx, pageId, *rq.TextContent)
+	default:
+		return wikierr.New(wikierr.ValidationFailed, "Provide a title or text_content")
+	}
+	if err != nil {
+		return parseDbErr(err)
+	}
+	if rq.TextContent != nil {
+		s.removePublishedDrafts(r, pageId)
+	}
+	bundle, err := s.repo.GetBundledPageByID(ctx, pageId)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	return encodeData(w, r, 200, bundle)
+}
+
+func (s *APIServer) deletePageV2(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	err = s.repo.DeleteBundledPage(r.Context(), pageId)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// Lists the subpages directly below the page, or all of them with ?recursive=true
+func (s *APIServer) listChildrenV2(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	pages, err := s.repo.GetSubpages(r.Context(), pageId, r.URL.Query().Get("recursive") == "true")
+	if err != nil {
+		return parseDbErr(err)
+	}
+	return encodeData(w, r, 200, *pages)
+}
+
+// Lists the pages using the page as a template, directly or through other
+// templates
+func (s *APIServer) listTemplateUsersV2(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	pages, err := s.repo.GetTemplateUsers(r.Context(), pageId)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	return encodeData(w, r, 200, *pages)
+}
+
+// Returns the headings of the latest revision as rendered, templates
+// included
+func (s *APIServer) getTocV2(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	ctx := r.Context()
+	bundle, err := s.repo.GetBundledPageByID(ctx, pageId)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	expansion, err := s.repo.ExpandTemplates(ctx, bundle.Text.Content)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	doc, err := s.markdown.Render([]byte(expansion.TextContent))
+	if err != nil {
+		return InternalServerErr(err)
+	}
+	return encodeData(w, r, 200, doc.Headings)
+}
+
+// Lists the sections of the latest revision without their content. Section
+// 0 is the text before the first heading.
+func (s *APIServer) listSectionsV2(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	sections, err := s.repo.GetSections(r.Context(), pageId)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	return encodeData(w, r, 200, sections)
+}
+
+// Returns a section by index or heading path, e.g. /sections/Install/Linux
+func (s *APIServer) getSectionV2(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	section, err := s.repo.GetSection(r.Context(), pageId, r.PathValue("section"))
+	if err != nil {
+		return parseDbErr(err)
+	}
+	return encodeData(w, r, 200, section)
+}
+
+// Replaces a section, publishing the page as a new revision
+func (s *APIServer) updateSectionV2(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	rq, err := decodeJSON[messages.UpdateSectionRequest](r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	ctx := r.Context()
+	err = s.repo.UpdateSection(ctx, pageId, r.PathValue("section"), rq.TextContent, rq.BaseRev)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	s.removePublishedDrafts(r, pageId)
+	bundle, err := s.repo.GetBundledPageByID(ctx, pageId)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	w.Header().Set("Location", fmt.Sprintf("/api/v2/pages/%d/revisions/%d", pageId, bundle.Revision.RevId))
+	return encodeData(w, r, 200, bundle)
+}
+
+func (s *APIServer) listRevisionsV2(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	revs, err := s.repo.GetPageRevsByID(r.Context(), pageId)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	return encodeData(w, r, 200, *revs)
+}
+
+func (s *APIServer) createRevisionV2(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	rq, err := decodeJSON[messages.NewRevisionRequest](r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	ctx := r.Context()
+	err = s.repo.NewPageRev(ctx, pageId, rq.TextContent)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	s.removePublishedDrafts(r, pageId)
+	bundle, err := s.repo.GetBundledPageByID(ctx, pageId)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	w.Header().Set("Location", fmt.Sprintf("/api/v2/pages/%d/revisions/%d", pageId, bundle.Revision.RevId))
+	return encodeData(w, r, 201, bundle)
+}
+
+// Returns a revision of the page together with its text
+func (s *APIServer) getRevisionV2(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	revId, err := parseUintParam(r, "rev_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	etag := revisionETag(revId)
+	// NOTE: Revisions never change, so a matching ETag needs no lookup
+	if immutableNotModified(w, r, etag) {
+		return nil
+	}
+	rev, err := s.repo.GetRevisionBundle(r.Context(), pageId, revId)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	immutable(w, r, etag)
+	return encodeData(w, r, 200, rev)
+}
+
+func (s *APIServer) getDraftV2(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	owner, err := parseDraftOwner(r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	baseRev, err := parseOptionalUintQuery(r, "base_rev")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	draft, err := s.repo.GetDraft(r.Context(), owner, pageId, baseRev)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	return encodeData(w, r, 200, draft)
+}
+
+func (s *APIServer) saveDraftV2(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	owner, err := parseDraftOwner(r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	rq, err := decodeJSON[messages.SaveDraftRequest](r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	draft := models.Draft{
+		Owner:   owner,
+		PageId:  pageId,
+		BaseRev: rq.BaseRev,
+		Content: rq.TextContent,
+	}
+	err = s.repo.SaveDraft(r.Context(), &draft)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *APIServer) deleteDraftV2(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUintParam(r, "page_id")
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	owner, err := parseDraftOwner(r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	err = s.repo.DeleteDrafts(r.Context(), owner, pageId)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *APIServer) renderMarkdownV2(w http.ResponseWriter, r *http.Request) error {
+	rq, err := decodeJSON[messages.RenderRequest](r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	expansion, err := s.repo.ExpandTemplates(r.Context(), rq.TextContent)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	doc, err := s.markdown.Render([]byte(expansion.TextContent))
+	if err != nil {
+		return InternalServerErr(err)
+	}
+	return encodeData(w, r, 200, &messages.RenderReply{Html: string(doc.HTML), Toc: doc.Headings})
+}
+
+func (s *APIServer) expandTemplatesV2(w http.ResponseWriter, r *http.Request) error {
+	rq, err := decodeJSON[messages.RenderRequest](r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	expansion, err := s.repo.ExpandTemplates(r.Context(), rq.TextContent)
+	if err != nil {
+		return parseDbErr(err)
+	}
+	return encodeData(w, r, 200, expansion)
+}
@@ -0,0 +1,50 @@
+package apiserver
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/dev-mackan/gowiki/internal/reposervice"
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+	"github.com/dev-mackan/gowiki/pkg/messages"
+)
+
+// Runs a list of operations in one transaction. The reply has a result per
+// operation. If one fails nothing is saved and the status is that of the failure.
+func (s *APIServer) runBatch(w http.ResponseWriter, r *http.Request) error {
+	rq, err := decodeJSON[messages.BatchRequest](r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	results, err := s.repo.RunBatch(r.Context(), rq.Operations, rq.DryRun)
+	var batchErr *reposervice.BatchError
+	if errors.As(err, &batchErr) {
+		log.Println(err)
+		wErr := wikierr.From(batchErr.Err)
+		reply := messages.BatchReply{DryRun: rq.DryRun}
+		for i := range rq.Operations {
+			switch {
+			case i < batchErr.Index:
+				reply.Results = append(reply.Results, messages.BatchOpResult{Status: messages.BatchOpRolledBack})
+			case i == batchErr.Index:
+				reply.Results = append(reply.Results, messages.BatchOpResult{
+					Status: messages.BatchOpFailed,
+					Error:  &messages.BatchOpError{Code: string(wErr.Code), Message: wErr.Message},
+				})
+			default:
+				reply.Results = append(reply.Results, messages.BatchOpResult{Status: messages.BatchOpSkipped})
+			}
+		}
+		return encodeJSON(w, r, codeToStatus(wErr.Code), reply)
+	}
+	if err != nil {
+		return parseDbErr(err)
+	}
+
+	reply := messages.BatchReply{Committed: !rq.DryRun, DryRun: rq.DryRun}
+	for i := range results {
+		reply.Results = append(reply.Results, messages.BatchOpResult{Status: messages.BatchOpOk, BatchResult: &results[i]})
+	}
+	return encodeJSON(w, r, 200, reply)
+}
@@ -0,0 +1,89 @@
+package apiserver
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dev-mackan/gowiki/internal/namespaces"
+	"github.com/dev-mackan/gowiki/internal/repos"
+	"github.com/dev-mackan/gowiki/internal/reposervice"
+	"github.com/dev-mackan/gowiki/internal/testutil"
+	"github.com/dev-mackan/gowiki/pkg/messages"
+)
+
+// Returns a server on an empty in-memory database and the database
+func newTestServer(t *testing.T) (*APIServer, *sql.DB) {
+	t.Helper()
+	db := testutil.NewDB(t)
+	repo := reposervice.NewRepoService(repos.NewSqlRepository(db), namespaces.New(nil, nil))
+	return &APIServer{repo: repo}, db
+}
+
+func TestRunBatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		status    int
+		committed bool
+		statuses  []string
+		code      string
+		pages     int
+	}{
+		{"committed",
+			`{"operations":[{"op":"create","title":"Home","text_content":"home"},{"op":"update_content","page_id":1,"text_content":"changed"}]}`,
+			200, true, []string{messages.BatchOpOk, messages.BatchOpOk}, "", 1},
+		{"dry run",
+			`{"dry_run":true,"operations":[{"op":"create","title":"Home"}]}`,
+			200, false, []string{messages.BatchOpOk}, "", 0},
+		{"failed operation is rolled back",
+			`{"operations":[{"op":"create","title":"Home"},{"op":"update_content","page_id":99},{"op":"delete","page_id":1}]}`,
+			404, false, []string{messages.BatchOpRolledBack, messages.BatchOpFailed, messages.BatchOpSkipped}, "page_not_found", 0},
+		{"invalid operation",
+			`{"operations":[{"op":"create","title":"Home"},{"op":"archive","page_id":1}]}`,
+			422, false, []string{messages.BatchOpRolledBack, messages.BatchOpFailed}, "validation_failed", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newTestServer(t)
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/v1/batch", strings.NewReader(tt.body))
+			if err := s.runBatch(w, r); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if w.Code != tt.status {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			var reply messages.BatchReply
+			if err := json.Unmarshal(w.Body.Bytes(), &reply); err != nil {
+				t.Fatal(err)
+			}
+			if reply.Committed != tt.committed || len(reply.Results) != len(tt.statuses) {
+				t.Fatalf("got %+v", reply)
+			}
+			for i, result := range reply.Results {
+				if result.Status != tt.statuses[i] {
+					t.Errorf("result %d: got status %q, want %q", i, result.Status, tt.statuses[i])
+				}
+				if (result.BatchResult != nil) != (result.Status == messages.BatchOpOk) {
+					t.Errorf("result %d: got page %+v", i, result.BatchResult)
+				}
+				if (result.Error != nil) != (result.Status == messages.BatchOpFailed) {
+					t.Errorf("result %d: got error %+v", i, result.Error)
+				}
+				if result.Error != nil && result.Error.Code != tt.code {
+					t.Errorf("result %d: got code %q, want %q", i, result.Error.Code, tt.code)
+				}
+			}
+			var pages int
+			if err := db.QueryRow(`SELECT COUNT(*) FROM Page`).Scan(&pages); err != nil {
+				t.Fatal(err)
+			}
+			if pages != tt.pages {
+				t.Errorf("got %d pages, want %d", pages, tt.pages)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package apiserver
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/dev-mackan/gowiki/pkg/models"
+)
+
+// Revisions never change once published, so their replies may be kept for
+// a year. Not public, as replies to requests with tokens must not be
+// shared between clients.
+const immutableCacheControl = "max-age=31536000, immutable"
+
+// Changes with every new revision of the page and when it is renamed
+func pageETag(page *models.Page) string {
+	sum := sha256.Sum256([]byte(page.Title))
+	return fmt.Sprintf(`"page-%d-%d-%x"`, page.PageId, page.LatestRev, sum[:4])
+}
+
+// A revision together with the page it belongs to
+func pageRevisionETag(page *models.Page, revId uint) string {
+	sum := sha256.Sum256([]byte(page.Title))
+	return fmt.Sprintf(`"page-%d-%d-%x-rev-%d"`, page.PageId, page.LatestRev, sum[:4], revId)
+}
+
+func revisionETag(revId uint) string {
+	return fmt.Sprintf(`"rev-%d"`, revId)
+}
+
+// Marks the reply as immutable and sets its ETag, see notModified
+func immutable(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("Cache-Control", immutableCacheControl)
+	return notModified(w, r, etag)
+}
+
+// Replies 304 Not Modified like immutable if the request's If-None-Match
+// matches etag, and otherwise sets no headers. Lets handlers of immutable
+// replies skip looking them up, the headers are set with immutable once
+// the reply is known to exist.
+func immutableNotModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	return immutable(w, r, etag)
+}
+
+// Sets the ETag of the reply. If the request's If-None-Match matches it,
+// replies 304 Not Modified and returns true, the handler must not reply
+// again.
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// Compares weakly, as If-None-Match does
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
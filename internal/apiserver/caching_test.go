@@ -0,0 +1,142 @@
+package apiserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dev-mackan/gowiki/pkg/models"
+)
+
+func TestEtagMatches(t *testing.T) {
+	tests := []struct {
+		ifNoneMatch string
+		etag        string
+		want        bool
+	}{
+		{`"rev-1"`, `"rev-1"`, true},
+		{`"rev-1"`, `"rev-2"`, false},
+		{`W/"rev-1"`, `"rev-1"`, true},
+		{`"rev-1"`, `W/"rev-1"`, true},
+		{`"rev-2", "rev-1"`, `"rev-1"`, true},
+		{`"rev-2","rev-3"`, `"rev-1"`, false},
+		{`*`, `"rev-1"`, true},
+		{``, `"rev-1"`, false},
+		{`rev-1`, `"rev-1"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ifNoneMatch, func(t *testing.T) {
+			if got := etagMatches(tt.ifNoneMatch, tt.etag); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		immutable   bool
+		want        bool
+	}{
+		{"no validator", "", false, false},
+		{"changed", `"rev-2"`, false, false},
+		{"unchanged", `"rev-1"`, false, true},
+		{"immutable", `"rev-1"`, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/revisions/1", nil)
+			if tt.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			var got bool
+			if tt.immutable {
+				got = immutable(w, r, revisionETag(1))
+			} else {
+				got = notModified(w, r, revisionETag(1))
+			}
+			if got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			if etag := w.Header().Get("ETag"); etag != `"rev-1"` {
+				t.Errorf("got ETag %q", etag)
+			}
+			if cc := w.Header().Get("Cache-Control"); (cc == immutableCacheControl) != tt.immutable {
+				t.Errorf("got Cache-Control %q", cc)
+			}
+			if tt.want && w.Code != http.StatusNotModified {
+				t.Errorf("got status %d, want 304", w.Code)
+			}
+			if !tt.want && w.Body.Len() != 0 {
+				t.Errorf("wrote %q", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPageETag(t *testing.T) {
+	page := models.Page{PageId: 1, LatestRev: 2, Title: "Home"}
+	etag := pageETag(&page)
+	for name, changed := range map[string]models.Page{
+		"new revision": {PageId: 1, LatestRev: 3, Title: "Home"},
+		"renamed":      {PageId: 1, LatestRev: 2, Title: "Start"},
+	} {
+		if pageETag(&changed) == etag {
+			t.Errorf("%s: ETag %s did not change", name, etag)
+		}
+	}
+	if pageRevisionETag(&page, 1) == pageRevisionETag(&page, 2) {
+		t.Error("revisions of a page have the same ETag")
+	}
+}
+
+func TestRevisionNotModifiedWithoutLookup(t *testing.T) {
+	s, db := newTestServer(t)
+	if _, err := s.repo.CreateBundledPage(context.Background(), "Home", "home"); err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/v1/revisions/{rev_id}/text/raw", makeApiHandlerFunc(s.getRawTextForPageWithRev))
+	mux.Handle("GET /api/v2/pages/{page_id}/revisions/{rev_id}", makeApiHandlerFunc(s.getRevisionV2))
+	// NOTE: In order, the database is closed for the last ones so any
+	// lookup fails
+	tests := []struct {
+		name        string
+		path        string
+		ifNoneMatch string
+		closeDB     bool
+		status      int
+	}{
+		{"v1 found", "/api/v1/revisions/1/text/raw", "", false, 200},
+		{"v2 found", "/api/v2/pages/1/revisions/1", `"rev-2"`, false, 200},
+		{"v1 missing", "/api/v1/revisions/9/text/raw", `"rev-8"`, false, 404},
+		{"v2 missing", "/api/v2/pages/1/revisions/9", "", false, 404},
+		{"v1 not modified", "/api/v1/revisions/9/text/raw", `"rev-9"`, true, 304},
+		{"v2 not modified", "/api/v2/pages/1/revisions/9", `"rev-9"`, true, 304},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.closeDB {
+				db.Close()
+			}
+			r := httptest.NewRequest("GET", tt.path, nil)
+			if tt.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			// NOTE: Errors must not be cached as if they were the revision
+			cached := w.Header().Get("Cache-Control") == immutableCacheControl && w.Header().Get("ETag") != ""
+			if cached != (tt.status != 404) {
+				t.Errorf("got headers %v", w.Header())
+			}
+		})
+	}
+}
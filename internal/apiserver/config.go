@@ -0,0 +1,36 @@
+package apiserver
+
+import (
+	"time"
+
+	"github.com/dev-mackan/gowiki/internal/auth"
+	"github.com/dev-mackan/gowiki/internal/markdown"
+	"github.com/dev-mackan/gowiki/internal/middleware"
+	"github.com/dev-mackan/gowiki/pkg/utils"
+)
+
+type APIServerConfig struct {
+	listenAddr string
+	rateLimit  *middleware.RateLimitConfig
+	// Shared with the gRPC server
+	auth *auth.Authenticator
+	// How long responses are kept for retries with the same Idempotency-Key
+	idempotencyWindow time.Duration
+	// Used by the render endpoint, should match the web server
+	markdown *markdown.Config
+}
+
+// authenticator should be shared with the gRPC server, so both accept the
+// same tokens
+func DefaultAPIServerConfig(authenticator *auth.Authenticator) *APIServerConfig {
+	rateLimit := middleware.DefaultRateLimitConfig()
+	// NOTE: The web server calls these for every preview and uncached page
+	rateLimit.ReadPaths = []string{"/api/v1/render", "/api/v1/expand", "/api/v2/render", "/api/v2/expand"}
+	return &APIServerConfig{
+		listenAddr:        ":3000",
+		rateLimit:         rateLimit,
+		auth:              authenticator,
+		idempotencyWindow: utils.EnvDuration("GOWIKI_IDEMPOTENCY_WINDOW", 24*time.Hour),
+		markdown:          markdown.DefaultConfig(),
+	}
+}
@@ -0,0 +1,22 @@
+package apiserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/dev-mackan/gowiki/pkg/messages"
+)
+
+// Executes a GraphQL query, see internal/graphqlapi/schema.graphql.
+// Errors are reported in the reply, which is always 200 once the request decoded.
+func (s *APIServer) graphQL(w http.ResponseWriter, r *http.Request) error {
+	rq, err := decodeJSON[messages.GraphQLRequest](r)
+	if err != nil {
+		return BadRequestErr(err)
+	}
+	if rq.Query == "" {
+		return BadRequestErr(fmt.Errorf("query cannot be empty"))
+	}
+	res := s.graphql.Exec(r.Context(), rq.Query, rq.OperationName, rq.Variables)
+	return encodeJSON(w, r, 200, res)
+}
@@ -0,0 +1,45 @@
+package apiserver
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// OpenAPI 3 description of every route in mount.
+// Keep it up to date when routes change, TestRoutesDocumented fails otherwise.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+func (s *APIServer) getOpenAPI(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	_, err := w.Write(openAPISpec)
+	return err
+}
+
+// Returns an error listing the routes that are missing from the spec
+func checkRoutesDocumented(spec []byte, routes []string) error {
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+	var missing []string
+	for _, route := range routes {
+		method, path, _ := strings.Cut(route, " ")
+		// NOTE: OpenAPI has no wildcards matching the rest of the path
+		path = strings.ReplaceAll(path, "...}", "}")
+		if _, ok := doc.Paths[path][strings.ToLower(method)]; !ok {
+			missing = append(missing, route)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("routes missing from the OpenAPI spec: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package apiserver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRoutesDocumented(t *testing.T) {
+	s := &APIServer{}
+	_, routes := s.mount()
+	if err := checkRoutesDocumented(openAPISpec, routes); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckRoutesDocumented(t *testing.T) {
+	spec := []byte(`{"paths": {
+		"/api/v2/pages": {"get": {}, "post": {}},
+		"/api/v2/pages/{page_id}/sections/{section}": {"get": {}}
+	}}`)
+	tests := []struct {
+		name    string
+		routes  []string
+		missing string
+	}{
+		{"documented", []string{"GET /api/v2/pages", "POST /api/v2/pages"}, ""},
+		{"wildcard", []string{"GET /api/v2/pages/{page_id}/sections/{section...}"}, ""},
+		{"missing method", []string{"DELETE /api/v2/pages"}, "DELETE /api/v2/pages"},
+		{"missing path", []string{"GET /api/v2/namespaces"}, "GET /api/v2/namespaces"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRoutesDocumented(spec, tt.routes)
+			if tt.missing == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.missing) {
+				t.Fatalf("got %v, want an error naming %s", err, tt.missing)
+			}
+		})
+	}
+}
+
+func TestCheckRoutesDocumentedInvalidSpec(t *testing.T) {
+	if err := checkRoutesDocumented([]byte("{"), nil); err == nil {
+		t.Fatal("expected an error for an invalid spec")
+	}
+}
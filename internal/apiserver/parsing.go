@@ -1,9 +1,13 @@
 package apiserver
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+
+	"github.com/dev-mackan/gowiki/pkg/messages"
+	"github.com/dev-mackan/gowiki/pkg/utils"
 )
 
 func parseUintParam(r *http.Request, param string) (uint, error) {
@@ -18,3 +22,20 @@ func parseUintParam(r *http.Request, param string) (uint, error) {
 	}
 	return uint(paramU64), nil
 }
+
+// Returns 0 if the query parameter is not set
+func parseOptionalUintQuery(r *http.Request, param string) (uint, error) {
+	paramStr := r.URL.Query().Get(param)
+	if paramStr == "" {
+		return 0, nil
+	}
+	return utils.ParseUintFromStr(paramStr)
+}
+
+func parseDraftOwner(r *http.Request) (string, error) {
+	owner := r.Header.Get(messages.DraftOwnerHeader)
+	if owner == "" {
+		return "", errors.New("missing " + messages.DraftOwnerHeader + " header")
+	}
+	return owner, nil
+}
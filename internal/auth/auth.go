@@ -0,0 +1,70 @@
+// Package auth checks the bearer tokens of API clients. The same check is
+// used by the HTTP middleware and the gRPC interceptors.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/dev-mackan/gowiki/pkg/utils"
+)
+
+type Authenticator struct {
+	// Hashed so comparisons take the same time whatever the token length
+	tokens [][sha256.Size]byte
+}
+
+// With no tokens every request is allowed
+func NewAuthenticator(tokens []string) *Authenticator {
+	a := &Authenticator{}
+	for _, token := range tokens {
+		a.tokens = append(a.tokens, sha256.Sum256([]byte(token)))
+	}
+	return a
+}
+
+// Reads the accepted tokens from GOWIKI_API_TOKENS, a comma separated list
+func DefaultAuthenticator() *Authenticator {
+	return NewAuthenticator(utils.EnvList("GOWIKI_API_TOKENS"))
+}
+
+func (a *Authenticator) Enabled() bool {
+	return len(a.tokens) > 0
+}
+
+// Reports whether a request with the token may use the API
+func (a *Authenticator) Allow(token string) bool {
+	if !a.Enabled() {
+		return true
+	}
+	sum := sha256.Sum256([]byte(token))
+	ok := 0
+	for _, t := range a.tokens {
+		ok |= subtle.ConstantTimeCompare(sum[:], t[:])
+	}
+	return ok == 1
+}
+
+// Returns the token of an Authorization header value, or "" if it is not a bearer token
+func BearerToken(header string) string {
+	if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+type tokenKey struct{}
+
+// Returns a context carrying the token of the request, so the repo
+// service can check per namespace permissions
+func WithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenKey{}, token)
+}
+
+// Returns the token stored by WithToken, or ""
+func TokenFrom(ctx context.Context) string {
+	token, _ := ctx.Value(tokenKey{}).(string)
+	return token
+}
@@ -0,0 +1,142 @@
+// Package frontmatter reads the YAML front matter at the top of page
+// content into page properties, e.g.
+//
+//	---
+//	owner: alice
+//	tags: [runbook, database]
+//	---
+//	# The page
+package frontmatter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Splits content into its front matter and the body after it. ok is false
+// if the content has no front matter, the body then being all of it.
+func Split(content string) (front string, body string, ok bool) {
+	rest, found := cutLine(content, "---")
+	if !found {
+		return "", content, false
+	}
+	for i := 0; i < len(rest); {
+		end := strings.IndexByte(rest[i:], '\n')
+		if end < 0 {
+			end = len(rest)
+		} else {
+			end += i + 1
+		}
+		line := strings.TrimRight(rest[i:end], "\r\n")
+		if line == "---" || line == "..." {
+			return rest[:i], rest[end:], true
+		}
+		i = end
+	}
+	return "", content, false
+}
+
+// Returns the content without its front matter
+func Body(content string) string {
+	_, body, _ := Split(content)
+	return body
+}
+
+// Parses the front matter of content, which has to be a YAML mapping.
+// Returns nil if there is none.
+func Parse(content string) (map[string]any, error) {
+	front, _, ok := Split(content)
+	if !ok {
+		return nil, nil
+	}
+	var props map[string]any
+	if err := yaml.Unmarshal([]byte(front), &props); err != nil {
+		return nil, fmt.Errorf("the front matter is not a YAML mapping: %w", err)
+	}
+	for key, value := range props {
+		props[key] = normalize(value)
+	}
+	return props, nil
+}
+
+// Makes YAML values encodable as JSON, mappings with keys other than
+// strings being the one YAML allows and JSON does not
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			v[key] = normalize(value)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = normalize(value)
+		}
+		return m
+	case []any:
+		for i, value := range v {
+			v[i] = normalize(value)
+		}
+		return v
+	case time.Time:
+		// NOTE: Dates are kept as written, e.g. 2024-05-01
+		if v.Equal(v.Truncate(24 * time.Hour)) {
+			return v.Format(time.DateOnly)
+		}
+		return v.Format(time.RFC3339)
+	default:
+		return v
+	}
+}
+
+// A property value as it is indexed
+type Property struct {
+	Name  string
+	Value string
+}
+
+// Flattens properties into name and value pairs, sorted by name. Every
+// element of a list is a value of its own and nested mappings are named
+// by their path, e.g. contact.email.
+func Flatten(props map[string]any) []Property {
+	var flat []Property
+	flatten(&flat, "", props)
+	sort.SliceStable(flat, func(i, j int) bool { return flat[i].Name < flat[j].Name })
+	return flat
+}
+
+func flatten(flat *[]Property, name string, v any) {
+	switch v := v.(type) {
+	case nil:
+	case map[string]any:
+		for key, value := range v {
+			if name != "" {
+				key = name + "." + key
+			}
+			flatten(flat, key, value)
+		}
+	case []any:
+		for _, value := range v {
+			flatten(flat, name, value)
+		}
+	default:
+		*flat = append(*flat, Property{Name: name, Value: fmt.Sprint(v)})
+	}
+}
+
+// Returns what follows the first line of s if that line is line
+func cutLine(s string, line string) (string, bool) {
+	rest, ok := strings.CutPrefix(s, line)
+	if !ok {
+		return s, false
+	}
+	if rest, ok := strings.CutPrefix(rest, "\r\n"); ok {
+		return rest, true
+	}
+	return strings.CutPrefix(rest, "\n")
+}
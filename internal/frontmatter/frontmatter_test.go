@@ -0,0 +1,109 @@
+package frontmatter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		front   string
+		body    string
+		ok      bool
+	}{
+		{"front matter", "---\nowner: alice\n---\n# Page\n", "owner: alice\n", "# Page\n", true},
+		{"crlf", "---\r\nowner: alice\r\n---\r\n# Page\r\n", "owner: alice\r\n", "# Page\r\n", true},
+		{"dots end it", "---\nowner: alice\n...\n# Page", "owner: alice\n", "# Page", true},
+		{"empty", "---\n---\n# Page", "", "# Page", true},
+		{"nothing after", "---\nowner: alice\n---", "owner: alice\n", "", true},
+		{"no front matter", "# Page\n", "", "# Page\n", false},
+		{"unterminated", "---\nowner: alice\n# Page\n", "", "---\nowner: alice\n# Page\n", false},
+		{"not on the first line", "\n---\nowner: alice\n---\n", "", "\n---\nowner: alice\n---\n", false},
+		{"indented", " ---\nowner: alice\n---\n", "", " ---\nowner: alice\n---\n", false},
+		{"thematic break", "---\n\nText", "", "---\n\nText", false},
+		{"longer rule", "----\nowner: alice\n---\n", "", "----\nowner: alice\n---\n", false},
+		{"dashes in a value", "---\nrule: --- x\n---\nBody", "rule: --- x\n", "Body", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			front, body, ok := Split(tt.content)
+			if front != tt.front || body != tt.body || ok != tt.ok {
+				t.Errorf("got %q, %q, %v, want %q, %q, %v", front, body, ok, tt.front, tt.body, tt.ok)
+			}
+			if got := Body(tt.content); got != tt.body {
+				t.Errorf("got body %q, want %q", got, tt.body)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]any
+		fails   bool
+	}{
+		{"none", "# Page", nil, false},
+		{"empty", "---\n---\n", nil, false},
+		{"values", "---\nowner: alice\nreviewed: true\nversion: 2\n---\n",
+			map[string]any{"owner": "alice", "reviewed": true, "version": 2}, false},
+		{"date", "---\nreviewed: 2024-05-01\n---\n",
+			map[string]any{"reviewed": "2024-05-01"}, false},
+		{"time", "---\nreviewed: 2024-05-01T10:30:00Z\n---\n",
+			map[string]any{"reviewed": "2024-05-01T10:30:00Z"}, false},
+		{"non-string keys", "---\ncodes:\n  404: missing\n  true: yes\n---\n",
+			map[string]any{"codes": map[string]any{"404": "missing", "true": "yes"}}, false},
+		{"nested in a list", "---\nitems:\n  - {1: one, due: 2024-05-01}\n---\n",
+			map[string]any{"items": []any{map[string]any{"1": "one", "due": "2024-05-01"}}}, false},
+		{"list", "---\n- a\n- b\n---\n", nil, true},
+		{"scalar", "---\njust text\n---\n", nil, true},
+		{"invalid", "---\nowner: [alice\n---\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			props, err := Parse(tt.content)
+			if tt.fails {
+				if err == nil {
+					t.Fatalf("got %v, want an error", props)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(props, tt.want) {
+				t.Errorf("got %#v, want %#v", props, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	tests := []struct {
+		name  string
+		props map[string]any
+		want  []Property
+	}{
+		{"none", nil, nil},
+		{"values", map[string]any{"owner": "alice", "version": 2, "draft": false},
+			[]Property{{"draft", "false"}, {"owner", "alice"}, {"version", "2"}}},
+		{"list", map[string]any{"tags": []any{"runbook", "database"}},
+			[]Property{{"tags", "runbook"}, {"tags", "database"}}},
+		{"nested", map[string]any{"contact": map[string]any{"email": "ops@example.com", "chat": map[string]any{"channel": "#ops"}}},
+			[]Property{{"contact.chat.channel", "#ops"}, {"contact.email", "ops@example.com"}}},
+		{"list of mappings", map[string]any{"links": []any{map[string]any{"url": "a"}, map[string]any{"url": "b"}}},
+			[]Property{{"links.url", "a"}, {"links.url", "b"}}},
+		{"null", map[string]any{"owner": nil, "tags": []any{nil, "x"}},
+			[]Property{{"tags", "x"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Flatten(tt.props); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package graphqlapi
+
+import (
+	"context"
+	"sync"
+)
+
+// Loads the values of a group of sibling objects, e.g. the items of a list,
+// with one call the first time any of them asks for its value. A list of n
+// pages then costs one query per field instead of n.
+type batch[K comparable, V any] struct {
+	keys func(context.Context) ([]K, error)
+	load func(context.Context, []K) (map[K]V, error)
+	once sync.Once
+	vals map[K]V
+	err  error
+}
+
+func newBatch[K comparable, V any](
+	keys func(context.Context) ([]K, error),
+	load func(context.Context, []K) (map[K]V, error),
+) *batch[K, V] {
+	return &batch[K, V]{keys: keys, load: load}
+}
+
+// Returns the zero value for keys that were not found
+func (b *batch[K, V]) get(ctx context.Context, key K) (V, error) {
+	b.once.Do(func() {
+		keys, err := b.keys(ctx)
+		if err != nil {
+			b.err = err
+			return
+		}
+		b.vals, b.err = b.load(ctx, unique(keys))
+	})
+	return b.vals[key], b.err
+}
+
+func unique[K comparable](keys []K) []K {
+	seen := make(map[K]bool, len(keys))
+	res := make([]K, 0, len(keys))
+	for _, key := range keys {
+		if !seen[key] {
+			seen[key] = true
+			res = append(res, key)
+		}
+	}
+	return res
+}
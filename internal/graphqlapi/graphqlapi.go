@@ -0,0 +1,44 @@
+// Package graphqlapi exposes the repo service as a GraphQL schema
+package graphqlapi
+
+import (
+	_ "embed"
+	"log"
+
+	"github.com/dev-mackan/gowiki/internal/reposervice"
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schema string
+
+// Limits how deep queries can nest, e.g. backlinks of backlinks
+const maxDepth = 8
+
+// Panics if the resolvers do not match the embedded schema
+func NewSchema(repo *reposervice.RepoService) *graphql.Schema {
+	return graphql.MustParseSchema(schema, &resolver{repo}, graphql.MaxDepth(maxDepth))
+}
+
+// Errors carry the same code as the REST problem details in their extensions
+type resolverError struct {
+	err *wikierr.Error
+}
+
+func resolverErr(err error) error {
+	wErr := wikierr.From(err)
+	if wErr.Code == wikierr.Internal {
+		log.Println(err)
+	}
+	return &resolverError{wErr}
+}
+
+// Only the message is safe to show to clients
+func (e *resolverError) Error() string {
+	return e.err.Message
+}
+
+func (e *resolverError) Extensions() map[string]any {
+	return map[string]any{"code": e.err.Code}
+}
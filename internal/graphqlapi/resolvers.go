@@ -0,0 +1,354 @@
+package graphqlapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dev-mackan/gowiki/internal/reposervice"
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+	"github.com/dev-mackan/gowiki/pkg/models"
+	"github.com/dev-mackan/gowiki/pkg/utils"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// Resolves the Query and Mutation types
+type resolver struct {
+	repo *reposervice.RepoService
+}
+
+func (r *resolver) Page(ctx context.Context, args struct {
+	ID    *graphql.ID
+	Title *string
+}) (*pageResolver, error) {
+	var page *models.Page
+	var err error
+	switch {
+	case args.ID != nil:
+		pageId, perr := parseID(*args.ID)
+		if perr != nil {
+			return nil, perr
+		}
+		page, err = r.repo.GetPageByID(ctx, pageId)
+	case args.Title != nil:
+		page, err = r.repo.GetPageByTitle(ctx, *args.Title)
+	default:
+		return nil, resolverErr(wikierr.New(wikierr.ValidationFailed, "Provide an id or a title"))
+	}
+	if wikierr.CodeOf(err) == wikierr.PageNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, resolverErr(err)
+	}
+	return r.singlePage(page), nil
+}
+
+func (r *resolver) Pages(ctx context.Context, args struct{ Namespace *string }) ([]*pageResolver, error) {
+	var pages *[]*models.Page
+	var err error
+	if args.Namespace != nil {
+		pages, err = r.repo.GetPagesInNamespace(ctx, *args.Namespace)
+	} else {
+		pages, err = r.repo.GetPages(ctx)
+	}
+	if err != nil {
+		return nil, resolverErr(err)
+	}
+	return r.pageList(*pages), nil
+}
+
+func (r *resolver) Namespaces() []*namespaceResolver {
+	all := r.repo.GetNamespaces()
+	res := make([]*namespaceResolver, len(all))
+	for i := range all {
+		res[i] = &namespaceResolver{all[i]}
+	}
+	return res
+}
+
+func (r *resolver) Revision(ctx context.Context, args struct{ ID graphql.ID }) (*revisionResolver, error) {
+	revId, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	revs, err := r.repo.GetRevisionsByIDs(ctx, []uint{revId})
+	if err != nil {
+		return nil, resolverErr(err)
+	}
+	if len(*revs) == 0 {
+		return nil, nil
+	}
+	rev := (*revs)[0]
+	return &revisionResolver{rev, newRevisionSet(r.repo, fixedRevs(rev))}, nil
+}
+
+func (r *resolver) Text(ctx context.Context, args struct{ ID graphql.ID }) (*textResolver, error) {
+	textId, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	texts, err := r.repo.GetTextsByIDs(ctx, []uint{textId})
+	if err != nil {
+		return nil, resolverErr(err)
+	}
+	if len(*texts) == 0 {
+		return nil, nil
+	}
+	return &textResolver{(*texts)[0]}, nil
+}
+
+func (r *resolver) Search(ctx context.Context, args struct {
+	Query     string
+	Namespace *string
+}) ([]*pageResolver, error) {
+	pages, err := r.repo.SearchPages(ctx, args.Query, args.Namespace)
+	if err != nil {
+		return nil, resolverErr(err)
+	}
+	return r.pageList(*pages), nil
+}
+
+func (r *resolver) CreatePage(ctx context.Context, args struct {
+	Title   string
+	Content string
+}) (*pageResolver, error) {
+	pageId, err := r.repo.CreateBundledPage(ctx, args.Title, args.Content)
+	if err != nil {
+		return nil, resolverErr(err)
+	}
+	return r.fetchPage(ctx, pageId)
+}
+
+func (r *resolver) EditPage(ctx context.Context, args struct {
+	ID      graphql.ID
+	Content string
+}) (*pageResolver, error) {
+	pageId, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = r.repo.NewPageRev(ctx, pageId, args.Content)
+	if err != nil {
+		return nil, resolverErr(err)
+	}
+	return r.fetchPage(ctx, pageId)
+}
+
+func (r *resolver) RenamePage(ctx context.Context, args struct {
+	ID           graphql.ID
+	Title        string
+	MoveSubpages bool
+}) (*pageResolver, error) {
+	pageId, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = r.repo.UpdatePageTitle(ctx, pageId, args.Title, args.MoveSubpages)
+	if err != nil {
+		return nil, resolverErr(err)
+	}
+	return r.fetchPage(ctx, pageId)
+}
+
+func (r *resolver) DeletePage(ctx context.Context, args struct{ ID graphql.ID }) (graphql.ID, error) {
+	pageId, err := parseID(args.ID)
+	if err != nil {
+		return "", err
+	}
+	err = r.repo.DeleteBundledPage(ctx, pageId)
+	if err != nil {
+		return "", resolverErr(err)
+	}
+	return args.ID, nil
+}
+
+func (r *resolver) fetchPage(ctx context.Context, pageId uint) (*pageResolver, error) {
+	page, err := r.repo.GetPageByID(ctx, pageId)
+	if err != nil {
+		return nil, resolverErr(err)
+	}
+	return r.singlePage(page), nil
+}
+
+func (r *resolver) singlePage(page *models.Page) *pageResolver {
+	return &pageResolver{page, newPageSet(r.repo, fixedPages(page))}
+}
+
+func (r *resolver) pageList(pages []*models.Page) []*pageResolver {
+	set := newPageSet(r.repo, fixedPages(pages...))
+	return pageResolvers(pages, set)
+}
+
+type pageResolver struct {
+	page *models.Page
+	set  *pageSet
+}
+
+func pageResolvers(pages []*models.Page, set *pageSet) []*pageResolver {
+	res := make([]*pageResolver, len(pages))
+	for i, page := range pages {
+		res[i] = &pageResolver{page, set}
+	}
+	return res
+}
+
+func (p *pageResolver) ID() graphql.ID {
+	return toID(p.page.PageId)
+}
+
+func (p *pageResolver) Title() string {
+	return p.page.Title
+}
+
+func (p *pageResolver) DisplayTitle() string {
+	return p.page.DisplayTitle()
+}
+
+func (p *pageResolver) Namespace() string {
+	return p.page.Namespace
+}
+
+func (p *pageResolver) Name() string {
+	return p.page.Name()
+}
+
+func (p *pageResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: p.page.CreatedAt}
+}
+
+func (p *pageResolver) LatestRevision(ctx context.Context) (*revisionResolver, error) {
+	rev, err := p.set.latest.get(ctx, p.page.LatestRev)
+	if err != nil {
+		return nil, resolverErr(err)
+	}
+	if rev == nil {
+		return nil, resolverErr(wikierr.New(wikierr.RevisionNotFound, "Revision not found"))
+	}
+	return &revisionResolver{rev, p.set.latestSet()}, nil
+}
+
+func (p *pageResolver) Revisions(ctx context.Context) ([]*revisionResolver, error) {
+	revs, err := p.set.revisions.get(ctx, p.page.PageId)
+	if err != nil {
+		return nil, resolverErr(err)
+	}
+	set := p.set.revisionSet()
+	res := make([]*revisionResolver, len(revs))
+	for i, rev := range revs {
+		res[i] = &revisionResolver{rev, set}
+	}
+	return res, nil
+}
+
+func (p *pageResolver) Backlinks(ctx context.Context) ([]*pageResolver, error) {
+	pages, err := p.set.backlinks.get(ctx, p.page.Title)
+	if err != nil {
+		return nil, resolverErr(err)
+	}
+	return pageResolvers(pages, p.set.backlinkSet()), nil
+}
+
+func (p *pageResolver) Parent(ctx context.Context) (*pageResolver, error) {
+	if p.page.ParentTitle() == "" {
+		return nil, nil
+	}
+	parent, err := p.set.parents.get(ctx, p.page.ParentTitle())
+	if err != nil {
+		return nil, resolverErr(err)
+	}
+	if parent == nil {
+		return nil, nil
+	}
+	return &pageResolver{parent, p.set.parentSet()}, nil
+}
+
+func (p *pageResolver) Children(ctx context.Context) ([]*pageResolver, error) {
+	pages, err := p.set.children.get(ctx, p.page.Title)
+	if err != nil {
+		return nil, resolverErr(err)
+	}
+	return pageResolvers(pages, p.set.childSet()), nil
+}
+
+func (p *pageResolver) UsedBy(ctx context.Context) ([]*pageResolver, error) {
+	pages, err := p.set.usedBy.get(ctx, p.page.Title)
+	if err != nil {
+		return nil, resolverErr(err)
+	}
+	return pageResolvers(pages, p.set.usedBySet()), nil
+}
+
+type revisionResolver struct {
+	rev *models.Revision
+	set *revisionSet
+}
+
+func (r *revisionResolver) ID() graphql.ID {
+	return toID(r.rev.RevId)
+}
+
+func (r *revisionResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.rev.CreatedAt}
+}
+
+func (r *revisionResolver) Page(ctx context.Context) (*pageResolver, error) {
+	page, err := r.set.pages.get(ctx, r.rev.PageId)
+	if err != nil {
+		return nil, resolverErr(err)
+	}
+	if page == nil {
+		return nil, resolverErr(wikierr.New(wikierr.PageNotFound, "Page not found"))
+	}
+	return &pageResolver{page, r.set.pageSet()}, nil
+}
+
+func (r *revisionResolver) Text(ctx context.Context) (*textResolver, error) {
+	text, err := r.set.texts.get(ctx, r.rev.TextId)
+	if err != nil {
+		return nil, resolverErr(err)
+	}
+	if text == nil {
+		return nil, resolverErr(wikierr.New(wikierr.TextNotFound, "Text not found"))
+	}
+	return &textResolver{text}, nil
+}
+
+type textResolver struct {
+	text *models.Text
+}
+
+func (t *textResolver) ID() graphql.ID {
+	return toID(t.text.TextId)
+}
+
+func (t *textResolver) Content() string {
+	return t.text.Content
+}
+
+func (t *textResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: t.text.CreatedAt}
+}
+
+type namespaceResolver struct {
+	ns models.Namespace
+}
+
+func (n *namespaceResolver) Name() string {
+	return n.ns.Name
+}
+
+func (n *namespaceResolver) Protected() bool {
+	return n.ns.Protected
+}
+
+func toID(id uint) graphql.ID {
+	return graphql.ID(fmt.Sprint(id))
+}
+
+func parseID(id graphql.ID) (uint, error) {
+	res, err := utils.ParseUintFromStr(string(id))
+	if err != nil {
+		return 0, resolverErr(wikierr.Wrap(err, wikierr.BadRequest, "Invalid id"))
+	}
+	return res, nil
+}
@@ -0,0 +1,298 @@
+package graphqlapi
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/dev-mackan/gowiki/internal/reposervice"
+	"github.com/dev-mackan/gowiki/pkg/models"
+)
+
+// Pages resolved as siblings. The fields of every page in the set are
+// loaded together, and the objects they return form sets of their own.
+type pageSet struct {
+	pages func(context.Context) ([]*models.Page, error)
+	// By revision id
+	latest *batch[uint, *models.Revision]
+	// By page id
+	revisions *batch[uint, []*models.Revision]
+	// By title
+	backlinks *batch[string, []*models.Page]
+	// By parent title
+	parents *batch[string, *models.Page]
+	// By title
+	children *batch[string, []*models.Page]
+	// By title
+	usedBy *batch[string, []*models.Page]
+
+	latestSet   func() *revisionSet
+	revisionSet func() *revisionSet
+	backlinkSet func() *pageSet
+	parentSet   func() *pageSet
+	childSet    func() *pageSet
+	usedBySet   func() *pageSet
+}
+
+func newPageSet(repo *reposervice.RepoService, pages func(context.Context) ([]*models.Page, error)) *pageSet {
+	set := &pageSet{pages: pages}
+	set.latest = newBatch(
+		func(ctx context.Context) ([]uint, error) {
+			return keysOf(ctx, pages, func(p *models.Page) uint { return p.LatestRev })
+		},
+		func(ctx context.Context, revIds []uint) (map[uint]*models.Revision, error) {
+			revs, err := repo.GetRevisionsByIDs(ctx, revIds)
+			if err != nil {
+				return nil, err
+			}
+			return byKey(*revs, func(r *models.Revision) uint { return r.RevId }), nil
+		},
+	)
+	set.revisions = newBatch(
+		func(ctx context.Context) ([]uint, error) {
+			return keysOf(ctx, pages, func(p *models.Page) uint { return p.PageId })
+		},
+		func(ctx context.Context, pageIds []uint) (map[uint][]*models.Revision, error) {
+			revs, err := repo.GetRevsByPageIDs(ctx, pageIds)
+			if err != nil {
+				return nil, err
+			}
+			res := make(map[uint][]*models.Revision, len(pageIds))
+			for _, rev := range *revs {
+				res[rev.PageId] = append(res[rev.PageId], rev)
+			}
+			return res, nil
+		},
+	)
+	set.backlinks = newBatch(
+		func(ctx context.Context) ([]string, error) {
+			return keysOf(ctx, pages, func(p *models.Page) string { return p.Title })
+		},
+		repo.GetBacklinks,
+	)
+	set.parents = newBatch(
+		func(ctx context.Context) ([]string, error) {
+			titles, err := keysOf(ctx, pages, func(p *models.Page) string { return p.ParentTitle() })
+			if err != nil {
+				return nil, err
+			}
+			// NOTE: Top level pages have no parent to look for
+			return slices.DeleteFunc(titles, func(t string) bool { return t == "" }), nil
+		},
+		repo.GetPagesByTitles,
+	)
+	set.children = newBatch(
+		func(ctx context.Context) ([]string, error) {
+			return keysOf(ctx, pages, func(p *models.Page) string { return p.Title })
+		},
+		repo.GetChildrenByTitles,
+	)
+	set.usedBy = newBatch(
+		func(ctx context.Context) ([]string, error) {
+			return keysOf(ctx, pages, func(p *models.Page) string { return p.Title })
+		},
+		repo.GetTemplateUsersByTitles,
+	)
+
+	set.latestSet = sync.OnceValue(func() *revisionSet {
+		return newRevisionSet(repo, func(ctx context.Context) ([]*models.Revision, error) {
+			ps, err := pages(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var revs []*models.Revision
+			for _, p := range ps {
+				rev, err := set.latest.get(ctx, p.LatestRev)
+				if err != nil {
+					return nil, err
+				}
+				if rev != nil {
+					revs = append(revs, rev)
+				}
+			}
+			return revs, nil
+		})
+	})
+	set.revisionSet = sync.OnceValue(func() *revisionSet {
+		return newRevisionSet(repo, func(ctx context.Context) ([]*models.Revision, error) {
+			ps, err := pages(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var revs []*models.Revision
+			for _, p := range ps {
+				pageRevs, err := set.revisions.get(ctx, p.PageId)
+				if err != nil {
+					return nil, err
+				}
+				revs = append(revs, pageRevs...)
+			}
+			return revs, nil
+		})
+	})
+	set.backlinkSet = sync.OnceValue(func() *pageSet {
+		return newPageSet(repo, func(ctx context.Context) ([]*models.Page, error) {
+			ps, err := pages(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var links []*models.Page
+			for _, p := range ps {
+				pageLinks, err := set.backlinks.get(ctx, p.Title)
+				if err != nil {
+					return nil, err
+				}
+				links = append(links, pageLinks...)
+			}
+			return links, nil
+		})
+	})
+	set.parentSet = sync.OnceValue(func() *pageSet {
+		return newPageSet(repo, func(ctx context.Context) ([]*models.Page, error) {
+			ps, err := pages(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var parents []*models.Page
+			for _, p := range ps {
+				if p.ParentTitle() == "" {
+					continue
+				}
+				parent, err := set.parents.get(ctx, p.ParentTitle())
+				if err != nil {
+					return nil, err
+				}
+				if parent != nil {
+					parents = append(parents, parent)
+				}
+			}
+			return parents, nil
+		})
+	})
+	set.childSet = sync.OnceValue(func() *pageSet {
+		return newPageSet(repo, func(ctx context.Context) ([]*models.Page, error) {
+			ps, err := pages(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var children []*models.Page
+			for _, p := range ps {
+				pageChildren, err := set.children.get(ctx, p.Title)
+				if err != nil {
+					return nil, err
+				}
+				children = append(children, pageChildren...)
+			}
+			return children, nil
+		})
+	})
+	set.usedBySet = sync.OnceValue(func() *pageSet {
+		return newPageSet(repo, func(ctx context.Context) ([]*models.Page, error) {
+			ps, err := pages(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var users []*models.Page
+			for _, p := range ps {
+				pageUsers, err := set.usedBy.get(ctx, p.Title)
+				if err != nil {
+					return nil, err
+				}
+				users = append(users, pageUsers...)
+			}
+			return users, nil
+		})
+	})
+	return set
+}
+
+// Revisions resolved as siblings, see pageSet
+type revisionSet struct {
+	// By text id
+	texts *batch[uint, *models.Text]
+	// By page id
+	pages *batch[uint, *models.Page]
+
+	pageSet func() *pageSet
+}
+
+func newRevisionSet(repo *reposervice.RepoService, revs func(context.Context) ([]*models.Revision, error)) *revisionSet {
+	set := &revisionSet{}
+	set.texts = newBatch(
+		func(ctx context.Context) ([]uint, error) {
+			return keysOf(ctx, revs, func(r *models.Revision) uint { return r.TextId })
+		},
+		func(ctx context.Context, textIds []uint) (map[uint]*models.Text, error) {
+			texts, err := repo.GetTextsByIDs(ctx, textIds)
+			if err != nil {
+				return nil, err
+			}
+			return byKey(*texts, func(t *models.Text) uint { return t.TextId }), nil
+		},
+	)
+	set.pages = newBatch(
+		func(ctx context.Context) ([]uint, error) {
+			return keysOf(ctx, revs, func(r *models.Revision) uint { return r.PageId })
+		},
+		func(ctx context.Context, pageIds []uint) (map[uint]*models.Page, error) {
+			pages, err := repo.GetPagesByIDs(ctx, pageIds)
+			if err != nil {
+				return nil, err
+			}
+			return byKey(*pages, func(p *models.Page) uint { return p.PageId }), nil
+		},
+	)
+	set.pageSet = sync.OnceValue(func() *pageSet {
+		return newPageSet(repo, func(ctx context.Context) ([]*models.Page, error) {
+			rs, err := revs(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var pages []*models.Page
+			for _, r := range rs {
+				page, err := set.pages.get(ctx, r.PageId)
+				if err != nil {
+					return nil, err
+				}
+				if page != nil {
+					pages = append(pages, page)
+				}
+			}
+			return pages, nil
+		})
+	})
+	return set
+}
+
+func fixedPages(pages ...*models.Page) func(context.Context) ([]*models.Page, error) {
+	return func(context.Context) ([]*models.Page, error) {
+		return pages, nil
+	}
+}
+
+func fixedRevs(revs ...*models.Revision) func(context.Context) ([]*models.Revision, error) {
+	return func(context.Context) ([]*models.Revision, error) {
+		return revs, nil
+	}
+}
+
+// Returns the key of every item
+func keysOf[T any, K any](ctx context.Context, items func(context.Context) ([]T, error), key func(T) K) ([]K, error) {
+	all, err := items(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]K, len(all))
+	for i, item := range all {
+		keys[i] = key(item)
+	}
+	return keys, nil
+}
+
+func byKey[K comparable, V any](vals []V, key func(V) K) map[K]V {
+	res := make(map[K]V, len(vals))
+	for _, v := range vals {
+		res[key(v)] = v
+	}
+	return res
+}
@@ -0,0 +1,20 @@
+package grpcserver
+
+import (
+	"github.com/dev-mackan/gowiki/internal/auth"
+)
+
+type GRPCServerConfig struct {
+	listenAddr string
+	// Shared with the HTTP API
+	auth *auth.Authenticator
+}
+
+// authenticator should be shared with the HTTP API, so both accept the
+// same tokens
+func DefaultGRPCServerConfig(authenticator *auth.Authenticator) *GRPCServerConfig {
+	return &GRPCServerConfig{
+		listenAddr: ":3002",
+		auth:       authenticator,
+	}
+}
@@ -0,0 +1,59 @@
+package grpcserver
+
+import (
+	"github.com/dev-mackan/gowiki/pkg/models"
+	"github.com/dev-mackan/gowiki/pkg/wikipb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toPage(p *models.Page) *wikipb.Page {
+	return &wikipb.Page{
+		PageId:    uint64(p.PageId),
+		Title:     p.Title,
+		LatestRev: uint64(p.LatestRev),
+		CreatedAt: timestamppb.New(p.CreatedAt),
+		Namespace: p.Namespace,
+	}
+}
+
+func toRevision(r *models.Revision) *wikipb.Revision {
+	return &wikipb.Revision{
+		RevId:     uint64(r.RevId),
+		PageId:    uint64(r.PageId),
+		TextId:    uint64(r.TextId),
+		CreatedAt: timestamppb.New(r.CreatedAt),
+	}
+}
+
+func toText(t *models.Text) *wikipb.Text {
+	return &wikipb.Text{
+		TextId:    uint64(t.TextId),
+		Content:   t.Content,
+		CreatedAt: timestamppb.New(t.CreatedAt),
+	}
+}
+
+func toPageBundle(b *models.PageBundle) *wikipb.PageBundle {
+	return &wikipb.PageBundle{
+		Page:     toPage(b.Page),
+		Revision: toRevision(b.Revision),
+		Text:     toText(b.Text),
+	}
+}
+
+var changeKinds = map[models.PageChangeKind]wikipb.PageChange_Kind{
+	models.PageCreated: wikipb.PageChange_KIND_CREATED,
+	models.PageEdited:  wikipb.PageChange_KIND_EDITED,
+	models.PageRenamed: wikipb.PageChange_KIND_RENAMED,
+	models.PageDeleted: wikipb.PageChange_KIND_DELETED,
+}
+
+func toPageChange(c *models.PageChange) *wikipb.PageChange {
+	return &wikipb.PageChange{
+		Kind:   changeKinds[c.Kind],
+		PageId: uint64(c.PageId),
+		Title:  c.Title,
+		RevId:  uint64(c.RevId),
+		At:     timestamppb.New(c.At),
+	}
+}
@@ -0,0 +1,40 @@
+package grpcserver
+
+import (
+	"log"
+
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Translates errors to gRPC statuses, the counterpart of the HTTP problem details
+func statusErr(err error) error {
+	wErr := wikierr.From(err)
+	code := codeToGRPC(wErr.Code)
+	if code == codes.Internal {
+		log.Println(err)
+	}
+	return status.Error(code, wErr.Message)
+}
+
+func codeToGRPC(code wikierr.Code) codes.Code {
+	switch code {
+	case wikierr.PageNotFound, wikierr.RevisionNotFound, wikierr.TextNotFound, wikierr.DraftNotFound, wikierr.SectionNotFound:
+		return codes.NotFound
+	case wikierr.DuplicateTitle:
+		return codes.AlreadyExists
+	case wikierr.EditConflict, wikierr.RequestInProgress:
+		return codes.Aborted
+	case wikierr.ValidationFailed, wikierr.BadRequest:
+		return codes.InvalidArgument
+	case wikierr.Unauthorized:
+		return codes.Unauthenticated
+	case wikierr.Forbidden:
+		return codes.PermissionDenied
+	case wikierr.RateLimited:
+		return codes.ResourceExhausted
+	default:
+		return codes.Internal
+	}
+}
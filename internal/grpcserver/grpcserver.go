@@ -0,0 +1,187 @@
+// Package grpcserver serves the wiki over gRPC, see proto/gowiki/v1/wiki.proto
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+
+	"github.com/dev-mackan/gowiki/internal/reposervice"
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+	"github.com/dev-mackan/gowiki/pkg/models"
+	"github.com/dev-mackan/gowiki/pkg/wikipb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type GRPCServer struct {
+	wikipb.UnimplementedWikiServiceServer
+	listenAddr string
+	repo       *reposervice.RepoService
+	server     *grpc.Server
+}
+
+func NewGRPCServer(config *GRPCServerConfig, repo *reposervice.RepoService) *GRPCServer {
+	s := &GRPCServer{
+		listenAddr: config.listenAddr,
+		repo:       repo,
+		server: grpc.NewServer(
+			grpc.ChainUnaryInterceptor(loggerUnaryInterceptor, newAuthUnaryInterceptor(config.auth)),
+			grpc.ChainStreamInterceptor(loggerStreamInterceptor, newAuthStreamInterceptor(config.auth)),
+		),
+	}
+	wikipb.RegisterWikiServiceServer(s.server, s)
+	return s
+}
+
+func (s *GRPCServer) Run() error {
+	lis, err := net.Listen("tcp", s.listenAddr)
+	if err != nil {
+		return err
+	}
+	log.Println("GOWIKI-GRPC listening on: ", s.listenAddr)
+	return s.server.Serve(lis)
+}
+
+func (s *GRPCServer) ListPages(ctx context.Context, req *wikipb.ListPagesRequest) (*wikipb.ListPagesResponse, error) {
+	var pages *[]*models.Page
+	var err error
+	if req.Namespace != nil {
+		pages, err = s.repo.GetPagesInNamespace(ctx, req.GetNamespace())
+	} else {
+		pages, err = s.repo.GetPages(ctx)
+	}
+	if err != nil {
+		return nil, statusErr(err)
+	}
+	res := &wikipb.ListPagesResponse{}
+	for _, page := range *pages {
+		res.Pages = append(res.Pages, toPage(page))
+	}
+	return res, nil
+}
+
+func (s *GRPCServer) GetPage(ctx context.Context, req *wikipb.GetPageRequest) (*wikipb.GetPageResponse, error) {
+	var title string
+	switch key := req.Page.(type) {
+	case *wikipb.GetPageRequest_Title:
+		title = key.Title
+	case *wikipb.GetPageRequest_PageId:
+		if req.RevId == 0 {
+			bundle, err := s.repo.GetBundledPageByID(ctx, uint(key.PageId))
+			if err != nil {
+				return nil, statusErr(err)
+			}
+			return &wikipb.GetPageResponse{Bundle: toPageBundle(bundle)}, nil
+		}
+		page, err := s.repo.GetPageByID(ctx, uint(key.PageId))
+		if err != nil {
+			return nil, statusErr(err)
+		}
+		title = page.Title
+	default:
+		return nil, status.Error(codes.InvalidArgument, "Provide a page_id or a title")
+	}
+
+	var bundle *models.PageBundle
+	var err error
+	if req.RevId == 0 {
+		bundle, err = s.repo.GetBundledPageByTitle(ctx, title)
+	} else {
+		bundle, err = s.repo.GetBundledPageWithRev(ctx, title, uint(req.RevId))
+	}
+	if err != nil {
+		return nil, statusErr(err)
+	}
+	return &wikipb.GetPageResponse{Bundle: toPageBundle(bundle)}, nil
+}
+
+func (s *GRPCServer) CreatePage(ctx context.Context, req *wikipb.CreatePageRequest) (*wikipb.CreatePageResponse, error) {
+	pageId, err := s.repo.CreateBundledPage(ctx, req.Title, req.Content)
+	if err != nil {
+		return nil, statusErr(err)
+	}
+	bundle, err := s.repo.GetBundledPageByID(ctx, pageId)
+	if err != nil {
+		return nil, statusErr(err)
+	}
+	return &wikipb.CreatePageResponse{Bundle: toPageBundle(bundle)}, nil
+}
+
+func (s *GRPCServer) UpdatePage(ctx context.Context, req *wikipb.UpdatePageRequest) (*wikipb.UpdatePageResponse, error) {
+	pageId := uint(req.PageId)
+	var err error
+	switch {
+	case req.Title != nil && req.Content != nil:
+		err = s.repo.UpdateBundledPage(ctx, pageId, *req.Title, *req.Content, req.MoveSubpages)
+	case req.Title != nil:
+		err = s.repo.UpdatePageTitle(ctx, pageId, *req.Title, req.MoveSubpages)
+	case req.Content != nil:
+		err = s.repo.NewPageRev(ctx, pageId, *req.Content)
+	default:
+		err = wikierr.New(wikierr.ValidationFailed, "Provide a title or content")
+	}
+	if err != nil {
+		return nil, statusErr(err)
+	}
+	bundle, err := s.repo.GetBundledPageByID(ctx, pageId)
+	if err != nil {
+		return nil, statusErr(err)
+	}
+	return &wikipb.UpdatePageResponse{Bundle: toPageBundle(bundle)}, nil
+}
+
+func (s *GRPCServer) DeletePage(ctx context.Context, req *wikipb.DeletePageRequest) (*wikipb.DeletePageResponse, error) {
+	err := s.repo.DeleteBundledPage(ctx, uint(req.PageId))
+	if err != nil {
+		return nil, statusErr(err)
+	}
+	return &wikipb.DeletePageResponse{}, nil
+}
+
+func (s *GRPCServer) ListRevisions(ctx context.Context, req *wikipb.ListRevisionsRequest) (*wikipb.ListRevisionsResponse, error) {
+	revs, err := s.repo.GetPageRevsByID(ctx, uint(req.PageId))
+	if err != nil {
+		return nil, statusErr(err)
+	}
+	res := &wikipb.ListRevisionsResponse{}
+	for _, rev := range *revs {
+		res.Revisions = append(res.Revisions, toRevision(rev))
+	}
+	return res, nil
+}
+
+func (s *GRPCServer) GetText(ctx context.Context, req *wikipb.GetTextRequest) (*wikipb.GetTextResponse, error) {
+	text, err := s.repo.GetTextByRevID(ctx, uint(req.RevId))
+	if err != nil {
+		return nil, statusErr(err)
+	}
+	return &wikipb.GetTextResponse{Text: toText(text)}, nil
+}
+
+func (s *GRPCServer) WatchChanges(req *wikipb.WatchChangesRequest, stream grpc.ServerStreamingServer[wikipb.WatchChangesResponse]) error {
+	ctx := stream.Context()
+	sub := s.repo.SubscribeChanges(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case change, ok := <-sub.Changes():
+			if !ok {
+				if errors.Is(sub.Err(), reposervice.ErrChangesMissed) {
+					return status.Error(codes.ResourceExhausted, "Too slow to keep up with the changes, watch again")
+				}
+				return nil
+			}
+			if req.PageId != 0 && uint64(change.PageId) != req.PageId {
+				continue
+			}
+			err := stream.Send(&wikipb.WatchChangesResponse{Change: toPageChange(&change)})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
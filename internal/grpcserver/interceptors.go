@@ -0,0 +1,73 @@
+package grpcserver
+
+import (
+	"context"
+	"log"
+
+	"github.com/dev-mackan/gowiki/internal/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Checks the bearer token in the authorization metadata with the same
+// authenticator as the HTTP API. Returns the context with the token.
+func checkAuth(ctx context.Context, a *auth.Authenticator, method string) (context.Context, error) {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vals := md.Get("authorization"); len(vals) > 0 {
+			token = auth.BearerToken(vals[0])
+		}
+	}
+	if !a.Allow(token) {
+		log.Printf("Unauthenticated %s", method)
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+	}
+	return auth.WithToken(ctx, token), nil
+}
+
+// Replaces the context of a stream
+type tokenStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tokenStream) Context() context.Context {
+	return s.ctx
+}
+
+func newAuthUnaryInterceptor(a *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := checkAuth(ctx, a, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func newAuthStreamInterceptor(a *auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := checkAuth(ss.Context(), a, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &tokenStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// Logs calls like the HTTP logger middleware
+func loggerUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	log.Printf("GRPC-SERVER <-- %s", info.FullMethod)
+	res, err := handler(ctx, req)
+	log.Printf("GRPC-SERVER --> %s %s", status.Code(err), info.FullMethod)
+	return res, err
+}
+
+func loggerStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	log.Printf("GRPC-SERVER <-- %s", info.FullMethod)
+	err := handler(srv, ss)
+	log.Printf("GRPC-SERVER --> %s %s", status.Code(err), info.FullMethod)
+	return err
+}
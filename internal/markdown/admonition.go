@@ -0,0 +1,117 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	admonitionClass      = "admonition"
+	admonitionTitleClass = "admonition-title"
+)
+
+// The first line of a block quote that makes it an admonition, as on GitHub
+var admonitionMarker = regexp.MustCompile(`(?i)^\[!(note|tip|important|warning|caution)\]$`)
+
+var kindAdmonition = ast.NewNodeKind("Admonition")
+
+// A block quote starting with a marker such as [!WARNING], rendered as a
+// box with a title
+type admonition struct {
+	ast.BaseBlock
+	// Lower case, e.g. warning
+	Variant string
+}
+
+func (n *admonition) Kind() ast.NodeKind {
+	return kindAdmonition
+}
+
+func (n *admonition) Dump(src []byte, level int) {
+	ast.DumpHelper(n, src, level, map[string]string{"Variant": n.Variant}, nil)
+}
+
+// Turns block quotes such as
+//
+//	> [!WARNING]
+//	> Restarting drops all connections.
+//
+// into admonitions
+type admonitions struct{}
+
+func (admonitions) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(admonitionTransformer{}, 500)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(admonitionRenderer{}, 500)))
+}
+
+type admonitionTransformer struct{}
+
+func (admonitionTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	src := reader.Source()
+	var quotes []*ast.Blockquote
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if q, ok := n.(*ast.Blockquote); ok && entering {
+			quotes = append(quotes, q)
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, q := range quotes {
+		p, ok := q.FirstChild().(*ast.Paragraph)
+		if !ok || p.Lines().Len() == 0 {
+			continue
+		}
+		line := p.Lines().At(0)
+		m := admonitionMarker.FindSubmatch(bytes.TrimSpace(line.Value(src)))
+		if m == nil {
+			continue
+		}
+		// NOTE: The marker is plain text, as it is not a link reference
+		for c := p.FirstChild(); c != nil; {
+			next := c.NextSibling()
+			t, ok := c.(*ast.Text)
+			if !ok || t.Segment.Start >= line.Stop {
+				break
+			}
+			p.RemoveChild(p, c)
+			c = next
+		}
+		lines := text.NewSegments()
+		lines.AppendAll(p.Lines().Sliced(1, p.Lines().Len()))
+		p.SetLines(lines)
+		if p.FirstChild() == nil {
+			q.RemoveChild(q, p)
+		}
+		a := &admonition{Variant: strings.ToLower(string(m[1]))}
+		for c := q.FirstChild(); c != nil; c = q.FirstChild() {
+			a.AppendChild(a, c)
+		}
+		q.Parent().ReplaceChild(q.Parent(), q, a)
+	}
+}
+
+type admonitionRenderer struct{}
+
+func (admonitionRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindAdmonition, renderAdmonition)
+}
+
+func renderAdmonition(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		_, _ = w.WriteString("</div>\n")
+		return ast.WalkContinue, nil
+	}
+	a := node.(*admonition)
+	title := strings.ToUpper(a.Variant[:1]) + a.Variant[1:]
+	_, _ = fmt.Fprintf(w, "<div class=\"%s %s-%s\">\n<p class=\"%s\">%s</p>\n",
+		admonitionClass, admonitionClass, a.Variant, admonitionTitleClass, title)
+	return ast.WalkContinue, nil
+}
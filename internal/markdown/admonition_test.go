@@ -0,0 +1,105 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderAdmonitions(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want string
+	}{
+		{"marker and text", "> [!WARNING]\n> Restarting drops all connections.\n",
+			"<div class=\"admonition admonition-warning\">\n<p class=\"admonition-title\">Warning</p>\n<p>Restarting drops all connections.</p>\n</div>\n"},
+		{"marker only", "> [!NOTE]\n",
+			"<div class=\"admonition admonition-note\">\n<p class=\"admonition-title\">Note</p>\n</div>\n"},
+		{"lower case", "> [!tip]\n> Use it.\n",
+			"<div class=\"admonition admonition-tip\">\n<p class=\"admonition-title\">Tip</p>\n<p>Use it.</p>\n</div>\n"},
+		{"several blocks", "> [!CAUTION]\n> One\n>\n> - Two\n",
+			"<div class=\"admonition admonition-caution\">\n<p class=\"admonition-title\">Caution</p>\n<p>One</p>\n<ul>\n<li>Two</li>\n</ul>\n</div>\n"},
+		{"marker not on the first line", "> Text\n> [!NOTE]\n",
+			"<blockquote>\n<p>Text\n[!NOTE]</p>\n</blockquote>\n"},
+		{"text after the marker", "> [!TIP] inline\n",
+			"<blockquote>\n<p>[!TIP] inline</p>\n</blockquote>\n"},
+		{"unknown marker", "> [!FOO]\n> x\n",
+			"<blockquote>\n<p>[!FOO]\nx</p>\n</blockquote>\n"},
+		{"plain quote", "> Just a quote\n",
+			"<blockquote>\n<p>Just a quote</p>\n</blockquote>\n"},
+		{"nested", "> [!IMPORTANT]\n> > [!NOTE]\n> > Inner\n",
+			"<div class=\"admonition admonition-important\">\n<p class=\"admonition-title\">Important</p>\n<div class=\"admonition admonition-note\">\n<p class=\"admonition-title\">Note</p>\n<p>Inner</p>\n</div>\n</div>\n"},
+	}
+	r := NewRenderer(&Config{Extensions: []string{"admonitions"}})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := r.Render([]byte(tt.md))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(doc.HTML); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderExtensions(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		// Rendered with the extension, and not without it
+		want string
+	}{
+		{"tables", "| a |\n|:-:|\n| 1 |\n", `<td align="center">1</td>`},
+		{"strikethrough", "~~gone~~", "<del>gone</del>"},
+		{"autolinks", "See https://example.com", `<a href="https://example.com"`},
+		{"tasklists", "- [x] done", `<input checked="" disabled="" type="checkbox"`},
+		{"footnotes", "Text[^1]\n\n[^1]: Note", `<div class="footnotes"`},
+		{"definition-lists", "Term\n: Definition", "<dt>Term</dt>"},
+		{"admonitions", "> [!NOTE]\n> x", `<div class="admonition admonition-note">`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, names := range [][]string{{tt.name}, DefaultExtensions} {
+				doc, err := NewRenderer(&Config{Extensions: names}).Render([]byte(tt.md))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.Contains(string(doc.HTML), tt.want) {
+					t.Errorf("with %q: %s missing from %s", names, tt.want, doc.HTML)
+				}
+			}
+			doc, err := NewRenderer(&Config{}).Render([]byte(tt.md))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(doc.HTML), tt.want) {
+				t.Errorf("without extensions: %s in %s", tt.want, doc.HTML)
+			}
+		})
+	}
+}
+
+func TestExtensionsUnknown(t *testing.T) {
+	r := NewRenderer(&Config{Extensions: []string{"nosuchextension", "strikethrough"}})
+	doc, err := r.Render([]byte("~~gone~~"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(doc.HTML), "<del>gone</del>") {
+		t.Errorf("known extension not enabled: %s", doc.HTML)
+	}
+}
+
+func TestDefaultConfigExtensions(t *testing.T) {
+	t.Setenv("GOWIKI_MARKDOWN_EXTENSIONS", "gfm, footnotes")
+	if got := DefaultConfig().Extensions; strings.Join(got, ",") != "gfm,footnotes" {
+		t.Errorf("got %q", got)
+	}
+	// NOTE: Set but empty is plain CommonMark
+	t.Setenv("GOWIKI_MARKDOWN_EXTENSIONS", "")
+	if got := DefaultConfig().Extensions; len(got) != 0 {
+		t.Errorf("got %q, want none", got)
+	}
+}
@@ -0,0 +1,50 @@
+package markdown
+
+import (
+	"log"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// Enabled unless GOWIKI_MARKDOWN_EXTENSIONS says otherwise
+var DefaultExtensions = []string{"gfm", "footnotes", "admonitions", "definition-lists"}
+
+// Returns the goldmark extensions with the given names, allowing what they
+// render through the policy. gfm is short for tables, strikethrough,
+// autolinks and tasklists. Unknown names are logged and skipped.
+func extensions(names []string, policy *bluemonday.Policy) []goldmark.Extender {
+	var exts []goldmark.Extender
+	for _, name := range names {
+		switch name {
+		case "gfm":
+			exts = append(exts, extensions([]string{"tables", "strikethrough", "autolinks", "tasklists"}, policy)...)
+		case "tables":
+			// NOTE: The sanitiser removes style attributes, but allows align
+			exts = append(exts, extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)))
+		case "strikethrough":
+			exts = append(exts, extension.Strikethrough)
+		case "autolinks":
+			exts = append(exts, extension.Linkify)
+		case "tasklists":
+			exts = append(exts, extension.TaskList)
+			policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+			policy.AllowAttrs("checked", "disabled").OnElements("input")
+		case "footnotes":
+			exts = append(exts, extension.Footnote)
+			policy.AllowAttrs("class").Matching(regexp.MustCompile(`^footnote-(ref|backref)$`)).OnElements("a")
+			policy.AllowAttrs("class").Matching(regexp.MustCompile(`^footnotes$`)).OnElements("div")
+		case "definition-lists":
+			exts = append(exts, extension.DefinitionList)
+		case "admonitions":
+			exts = append(exts, admonitions{})
+			policy.AllowAttrs("class").Matching(regexp.MustCompile(`^` + admonitionClass + ` ` + admonitionClass + `-[a-z]+$`)).OnElements("div")
+			policy.AllowAttrs("class").Matching(regexp.MustCompile(`^` + admonitionTitleClass + `$`)).OnElements("p")
+		default:
+			log.Printf("markdown: unknown extension %q", name)
+		}
+	}
+	return exts
+}
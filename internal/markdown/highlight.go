@@ -0,0 +1,56 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+)
+
+// Highlights fenced code blocks of known languages, marking tokens with
+// classes rather than inline styles, see HighlightCSS. Attributes after the
+// language number and highlight lines:
+//
+//	```go {linenos=true, hl_lines=[2, "4-6"], linenostart=10}
+func highlighter(style string) goldmark.Extender {
+	return highlighting.NewHighlighting(
+		highlighting.WithStyle(style),
+		highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+	)
+}
+
+// Matches the class attributes chroma writes, e.g. "line hl"
+func highlightClasses() *regexp.Regexp {
+	var names []string
+	for _, name := range chroma.StandardTypes {
+		if name != "" {
+			names = append(names, regexp.QuoteMeta(name))
+		}
+	}
+	// NOTE: Longest first so a name is not cut short by its prefix
+	sort.Slice(names, func(i, j int) bool {
+		return len(names[i]) > len(names[j]) || len(names[i]) == len(names[j]) && names[i] < names[j]
+	})
+	class := "(?:" + strings.Join(names, "|") + ")"
+	return regexp.MustCompile(`^` + class + `(?: ` + class + `)*$`)
+}
+
+// Returns the stylesheet for highlighted code, empty if highlighting is
+// disabled
+func (m *Renderer) HighlightCSS() []byte {
+	return m.highlightCSS
+}
+
+func highlightCSS(style string) []byte {
+	var buf bytes.Buffer
+	formatter := chromahtml.New(chromahtml.WithClasses(true), chromahtml.WithLineNumbers(true))
+	// NOTE: Only fails if the buffer does
+	_ = formatter.WriteCSS(&buf, styles.Get(style))
+	return buf.Bytes()
+}
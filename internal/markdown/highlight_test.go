@@ -0,0 +1,76 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderHighlighted(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		// Must all be in the sanitised output
+		want []string
+	}{
+		{"line numbers", "```go {linenos=true,hl_lines=[2]}\na := 1\nb := 2\n```\n", []string{
+			`<pre class="chroma">`,
+			`<span class="line"><span class="ln">1</span>`,
+			`<span class="line hl"><span class="ln">2</span>`,
+			`<span class="nx">b</span>`,
+			`<span class="o">:=</span>`,
+			`<span class="mi">2</span>`,
+		}},
+		{"line number table", "```go {linenos=table,hl_lines=[2],linenostart=10}\na := 1\nb := 2\n```\n", []string{
+			`<div class="chroma">`,
+			`<table class="lntable">`,
+			`<td class="lntd">`,
+			`<span class="lnt">10`,
+			`<span class="hl"><span class="lnt">11`,
+			`<span class="line hl"><span class="cl">`,
+		}},
+		{"unknown language", "```nosuchlanguage\na := 1\n```\n", []string{
+			"<pre><code>a := 1\n</code></pre>",
+		}},
+	}
+	r := NewRenderer(&Config{HighlightStyle: "monokai"})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := r.Render([]byte(tt.md))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(doc.HTML), want) {
+					t.Errorf("%s missing from %s", want, doc.HTML)
+				}
+			}
+			if strings.Contains(string(doc.HTML), "style=") {
+				t.Errorf("inline style in %s", doc.HTML)
+			}
+		})
+	}
+}
+
+func TestHighlightClasses(t *testing.T) {
+	classes := highlightClasses()
+	for _, class := range []string{"chroma", "line", "line hl", "ln", "lnt", "lntd", "lntable", "cl", "nx", "kd", "hl"} {
+		if !classes.MatchString(class) {
+			t.Errorf("class %q is removed", class)
+		}
+	}
+	for _, class := range []string{"", "toc", "line toc", "nxx", "line  hl", "admonition"} {
+		if classes.MatchString(class) {
+			t.Errorf("class %q is allowed", class)
+		}
+	}
+}
+
+func TestRenderNotHighlighted(t *testing.T) {
+	doc, err := NewRenderer(&Config{}).Render([]byte("```go\na := 1\n```\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(doc.HTML), "chroma") {
+		t.Errorf("highlighted without a style: %s", doc.HTML)
+	}
+}
@@ -0,0 +1,165 @@
+package markdown
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"regexp"
+
+	"github.com/dev-mackan/gowiki/internal/frontmatter"
+	"github.com/dev-mackan/gowiki/pkg/models"
+	"github.com/dev-mackan/gowiki/pkg/utils"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+type Config struct {
+	// Raw HTML in markdown is omitted unless enabled.
+	// Only elements in the allowlist survive sanitisation.
+	AllowRawHTML     bool
+	RawHTMLAllowlist []string
+	// Pages with at least this many headings get a table of contents
+	// above the first one, 0 only places it at [[TOC]] markers
+	TocMinHeadings int
+	// A chroma style such as monokai, code blocks are not highlighted if
+	// empty. Unknown styles fall back to the default.
+	HighlightStyle string
+	// Names of the goldmark extensions to enable, see DefaultExtensions
+	Extensions []string
+}
+
+func DefaultConfig() *Config {
+	extensions := DefaultExtensions
+	// NOTE: Set but empty disables every extension
+	if _, ok := os.LookupEnv("GOWIKI_MARKDOWN_EXTENSIONS"); ok {
+		extensions = utils.EnvList("GOWIKI_MARKDOWN_EXTENSIONS")
+	}
+	return &Config{
+		AllowRawHTML:     os.Getenv("GOWIKI_ALLOW_RAW_HTML") == "true",
+		RawHTMLAllowlist: utils.EnvList("GOWIKI_RAW_HTML_ALLOWLIST"),
+		TocMinHeadings:   utils.EnvInt("GOWIKI_TOC_MIN_HEADINGS", 4),
+		HighlightStyle:   utils.EnvString("GOWIKI_HIGHLIGHT_STYLE", "monokai"),
+		Extensions:       extensions,
+	}
+}
+
+// Bump when a change to the renderer or its dependencies changes the HTML
+// it outputs, see Renderer.Version
+const rendererVersion = 1
+
+// Converts markdown to HTML that is safe to inject into a template.
+// Raw HTML in the markdown is omitted unless explicitly allowed, and the
+// output is always passed through an allowlist sanitiser.
+type Renderer struct {
+	md             goldmark.Markdown
+	policy         *bluemonday.Policy
+	tocMinHeadings int
+	highlightCSS   []byte
+	version        string
+}
+
+// Heading ids are slugs of the heading text, see slug
+var headingID = regexp.MustCompile(`^[\p{L}\p{M}\p{N}_-]+$`)
+
+func NewRenderer(config *Config) *Renderer {
+	var opts []goldmark.Option
+	policy := bluemonday.UGCPolicy()
+	if config.AllowRawHTML {
+		opts = append(opts, goldmark.WithRendererOptions(html.WithUnsafe()))
+		if len(config.RawHTMLAllowlist) > 0 {
+			policy.AllowElements(config.RawHTMLAllowlist...)
+		}
+	}
+	policy.AllowAttrs("id").Matching(headingID).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^(` + anchorClass + `|` + editClass + `)$`)).OnElements("a")
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^` + tocClass + `$`)).OnElements("div")
+	opts = append(opts, goldmark.WithExtensions(extensions(config.Extensions, policy)...))
+	var css []byte
+	if config.HighlightStyle != "" {
+		css = highlightCSS(config.HighlightStyle)
+		opts = append(opts, goldmark.WithExtensions(highlighter(config.HighlightStyle)))
+		policy.AllowAttrs("class").Matching(highlightClasses()).OnElements("pre", "span", "div", "table", "td")
+	}
+	return &Renderer{
+		md:             goldmark.New(opts...),
+		policy:         policy,
+		tocMinHeadings: config.TocMinHeadings,
+		highlightCSS:   css,
+		version:        version(config),
+	}
+}
+
+// Identifies the output of the renderer: renderers with the same version
+// render the same markdown to the same HTML
+func (m *Renderer) Version() string {
+	return m.version
+}
+
+func version(config *Config) string {
+	// NOTE: Encoding a struct of strings, ints and bools cannot fail
+	b, _ := json.Marshal(config)
+	sum := sha256.Sum256(b)
+	return fmt.Sprintf("%d-%x", rendererVersion, sum[:8])
+}
+
+// A rendered page
+type Document struct {
+	// Sanitised, safe to inject into a template
+	HTML template.HTML
+	// In the order of the page, including those in the table of contents
+	Headings []models.Heading
+}
+
+// Front matter at the top of the markdown is not rendered, see package
+// frontmatter. Headings get ids and anchor links, and a table of contents
+// is placed at [[TOC]] markers or, with enough headings, above the first.
+func (m *Renderer) Render(md []byte) (*Document, error) {
+	return m.render(md, func(models.Heading) string { return "" })
+}
+
+// Renders md, the source with its templates expanded, with a link to edit
+// each section of the source, see Sections. The headings of templates are
+// not in the source and get no link.
+func (m *Renderer) RenderEditable(md []byte, source string, editURL func(section int) string) (*Document, error) {
+	sections := Sections(source)[1:]
+	next := 0
+	return m.render(md, func(h models.Heading) string {
+		// NOTE: The rendered headings are matched to the source in order
+		for i := next; i < len(sections); i++ {
+			if sections[i].Level == h.Level && sections[i].Heading == h.Text {
+				next = i + 1
+				return editURL(sections[i].Index)
+			}
+		}
+		return ""
+	})
+}
+
+func (m *Renderer) render(md []byte, editURL func(models.Heading) string) (*Document, error) {
+	src := []byte(frontmatter.Body(string(md)))
+	doc := m.md.Parser().Parse(text.NewReader(src))
+	headings := anchorHeadings(doc, src, editURL)
+	var buf bytes.Buffer
+	if err := m.md.Renderer().Render(&buf, src, doc); err != nil {
+		return nil, err
+	}
+	out := placeToc(buf.Bytes(), headings, m.tocMinHeadings)
+	// NOTE: Only sanitised output may be marked as safe
+	return &Document{
+		HTML:     template.HTML(m.policy.SanitizeBytes(out)),
+		Headings: headings,
+	}, nil
+}
+
+func (m *Renderer) MarkdownToHTML(md []byte) (template.HTML, error) {
+	doc, err := m.Render(md)
+	if err != nil {
+		return "", err
+	}
+	return doc.HTML, nil
+}
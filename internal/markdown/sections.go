@@ -0,0 +1,122 @@
+package markdown
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/dev-mackan/gowiki/internal/frontmatter"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Only finds the headings, so the renderer options do not matter
+var sectionParser = goldmark.New().Parser()
+
+// A part of the markdown source that can be read and replaced on its own:
+// a heading with everything up to the next heading of the same or a higher
+// level, subsections included. Section 0 is the text before the first
+// heading, without the front matter.
+type Section struct {
+	Index int
+	// 0 for section 0
+	Level   int
+	Heading string
+	// The headings above it and its own, e.g. [Install Linux]
+	Path   []string
+	Anchor string
+	// Byte offsets of the section in the content
+	Start int
+	End   int
+}
+
+// Splits the content into sections. Only headings at the top level count,
+// not those in lists or block quotes.
+func Sections(content string) []Section {
+	body := frontmatter.Body(content)
+	offset := len(content) - len(body)
+	src := []byte(body)
+	doc := sectionParser.Parse(text.NewReader(src))
+
+	sections := []Section{{Start: offset, End: len(content)}}
+	used := make(map[string]bool)
+	var path []Section
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		h, ok := n.(*ast.Heading)
+		// NOTE: Empty headings have no lines to find them by
+		if !ok || h.Lines().Len() == 0 {
+			continue
+		}
+		start := offset + lineStart(src, h.Lines().At(0).Start)
+		if len(sections) == 1 {
+			sections[0].End = start
+		}
+		for i := len(sections) - 1; i > 0 && sections[i].Level >= h.Level; i-- {
+			if sections[i].End == len(content) {
+				sections[i].End = start
+			}
+		}
+		for len(path) > 0 && path[len(path)-1].Level >= h.Level {
+			path = path[:len(path)-1]
+		}
+		heading := plainText(h, src)
+		s := Section{
+			Index:   len(sections),
+			Level:   h.Level,
+			Heading: heading,
+			Anchor:  uniqueID(slug(heading), used),
+			Start:   start,
+			End:     len(content),
+		}
+		path = append(path, s)
+		for _, p := range path {
+			s.Path = append(s.Path, p.Heading)
+		}
+		sections = append(sections, s)
+	}
+	return sections
+}
+
+// Finds a section by its index, or by its path written as the headings
+// joined by "/", ignoring case, e.g. Install/Linux. Headings that are
+// numbers can only be found by index.
+func FindSection(sections []Section, id string) (Section, bool) {
+	if i, err := strconv.Atoi(id); err == nil {
+		if i < 0 || i >= len(sections) {
+			return Section{}, false
+		}
+		return sections[i], true
+	}
+	for _, s := range sections[1:] {
+		if strings.EqualFold(strings.Join(s.Path, "/"), id) {
+			return s, true
+		}
+	}
+	return Section{}, false
+}
+
+// Returns the content with the section replaced by text. The newlines that
+// ended the section are kept before the section after it, so replacing a
+// section with its own text returns the content unchanged.
+func ReplaceSection(content string, s Section, text string) string {
+	if s.End < len(content) {
+		text = strings.TrimRight(text, "\n")
+		if text != "" {
+			old := content[s.Start:s.End]
+			sep := old[len(strings.TrimRight(old, "\n")):]
+			if sep == "" {
+				// NOTE: Only an empty section 0 has no newline
+				sep = "\n\n"
+			}
+			text += sep
+		}
+	}
+	return content[:s.Start] + text + content[s.End:]
+}
+
+func lineStart(src []byte, i int) int {
+	for i > 0 && src[i-1] != '\n' {
+		i--
+	}
+	return i
+}
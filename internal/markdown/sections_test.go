@@ -0,0 +1,161 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sectionsDoc = `---
+owner: alice
+---
+Intro
+
+# Install
+
+Steps.
+
+## Linux
+apt install gowiki
+## macOS
+
+brew install gowiki
+
+
+# Usage
+Run it.
+`
+
+func TestSections(t *testing.T) {
+	type section struct {
+		level   int
+		heading string
+		path    []string
+		anchor  string
+		text    string
+	}
+	tests := []struct {
+		name    string
+		content string
+		want    []section
+	}{
+		{"no headings", "Just text\n", []section{
+			{text: "Just text\n"},
+		}},
+		{"empty", "", []section{
+			{text: ""},
+		}},
+		{"heading first", "# A\ntext\n", []section{
+			{text: ""},
+			{level: 1, heading: "A", path: []string{"A"}, anchor: "a", text: "# A\ntext\n"},
+		}},
+		{"nested", sectionsDoc, []section{
+			{text: "Intro\n\n"},
+			{level: 1, heading: "Install", path: []string{"Install"}, anchor: "install",
+				text: "# Install\n\nSteps.\n\n## Linux\napt install gowiki\n## macOS\n\nbrew install gowiki\n\n\n"},
+			{level: 2, heading: "Linux", path: []string{"Install", "Linux"}, anchor: "linux",
+				text: "## Linux\napt install gowiki\n"},
+			{level: 2, heading: "macOS", path: []string{"Install", "macOS"}, anchor: "macos",
+				text: "## macOS\n\nbrew install gowiki\n\n\n"},
+			{level: 1, heading: "Usage", path: []string{"Usage"}, anchor: "usage", text: "# Usage\nRun it.\n"},
+		}},
+		{"duplicate headings", "# A\n# A\n", []section{
+			{text: ""},
+			{level: 1, heading: "A", path: []string{"A"}, anchor: "a", text: "# A\n"},
+			{level: 1, heading: "A", path: []string{"A"}, anchor: "a-2", text: "# A\n"},
+		}},
+		{"setext and nested blocks", "Title\n=====\n> # Quoted\n- # Listed\n", []section{
+			{text: ""},
+			{level: 1, heading: "Title", path: []string{"Title"}, anchor: "title", text: "Title\n=====\n> # Quoted\n- # Listed\n"},
+		}},
+		{"headings in code", "```\n# not a heading\n```\n", []section{
+			{text: "```\n# not a heading\n```\n"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sections := Sections(tt.content)
+			if len(sections) != len(tt.want) {
+				t.Fatalf("got %d sections, want %d: %+v", len(sections), len(tt.want), sections)
+			}
+			for i, s := range sections {
+				want := tt.want[i]
+				got := section{s.Level, s.Heading, s.Path, s.Anchor, tt.content[s.Start:s.End]}
+				if s.Index != i || !reflect.DeepEqual(got, want) {
+					t.Errorf("section %d: got %+v (index %d), want %+v", i, got, s.Index, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFindSection(t *testing.T) {
+	sections := Sections(sectionsDoc)
+	tests := []struct {
+		id    string
+		index int
+		found bool
+	}{
+		{"0", 0, true},
+		{"3", 3, true},
+		{"5", 0, false},
+		{"-1", 0, false},
+		{"Install", 1, true},
+		{"install/LINUX", 2, true},
+		{"Linux", 0, false},
+		{"Usage", 4, true},
+		{"Missing", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			s, found := FindSection(sections, tt.id)
+			if found != tt.found || (found && s.Index != tt.index) {
+				t.Errorf("got section %d, %v, want %d, %v", s.Index, found, tt.index, tt.found)
+			}
+		})
+	}
+}
+
+func TestReplaceSection(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		section int
+		text    string
+		want    string
+	}{
+		{"first section", "Intro\n\n# A\na\n", 0, "New intro", "New intro\n\n# A\na\n"},
+		{"middle section", "# A\na\n\n# B\nb\n", 1, "# A\nchanged\n", "# A\nchanged\n\n# B\nb\n"},
+		{"last section", "# A\na\n\n# B\nb\n", 2, "# B\nchanged", "# A\na\n\n# B\nchanged"},
+		{"keeps a single newline", "# A\na\n# B\nb\n", 1, "# A\nchanged\n\n\n", "# A\nchanged\n# B\nb\n"},
+		{"keeps several blank lines", "# A\na\n\n\n\n# B\n", 1, "# A\nchanged", "# A\nchanged\n\n\n\n# B\n"},
+		{"empty section 0", "# A\na\n", 0, "Intro", "Intro\n\n# A\na\n"},
+		{"removed section", "# A\na\n\n# B\nb\n", 1, "", "# B\nb\n"},
+		{"subsections", sectionsDoc, 1, "# Install\n\nNone.\n", "---\nowner: alice\n---\nIntro\n\n# Install\n\nNone.\n\n\n# Usage\nRun it.\n"},
+		{"front matter is kept", sectionsDoc, 0, "Changed\n", "---\nowner: alice\n---\nChanged\n\n# Install\n\nSteps.\n\n## Linux\napt install gowiki\n## macOS\n\nbrew install gowiki\n\n\n# Usage\nRun it.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Sections(tt.content)[tt.section]
+			if got := ReplaceSection(tt.content, s, tt.text); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// Saving a section as it was read must not change the page
+func TestReplaceSectionUnchanged(t *testing.T) {
+	docs := []string{
+		sectionsDoc,
+		"# A\na\n# B\nb",
+		"# A\n\n\n\n# B\n",
+		"Intro\n# A\n## B\n### C\n## D\n\n# E\n",
+	}
+	for _, doc := range docs {
+		for _, s := range Sections(doc) {
+			if got := ReplaceSection(doc, s, doc[s.Start:s.End]); got != doc {
+				t.Errorf("section %d of %q: got %q", s.Index, doc, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/dev-mackan/gowiki/pkg/models"
+	"github.com/yuin/goldmark/ast"
+)
+
+const (
+	anchorClass = "anchor"
+	tocClass    = "toc"
+	editClass   = "edit-section"
+)
+
+// A paragraph of its own, as rendered
+var tocMarker = []byte("<p>[[TOC]]</p>")
+
+var firstHeading = regexp.MustCompile(`<h[1-6][ >]`)
+
+// Gives every heading an id and appends a link to it, and one to edit it
+// if editURL returns one. Returns the headings in the order of the document.
+func anchorHeadings(doc ast.Node, src []byte, editURL func(models.Heading) string) []models.Heading {
+	headings := make([]models.Heading, 0)
+	used := make(map[string]bool)
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		h, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		text := plainText(h, src)
+		id := uniqueID(slug(text), used)
+		h.SetAttributeString("id", []byte(id))
+		link := ast.NewLink()
+		link.Destination = []byte("#" + id)
+		link.SetAttributeString("class", []byte(anchorClass))
+		link.AppendChild(link, ast.NewString([]byte("#")))
+		h.AppendChild(h, ast.NewString([]byte(" ")))
+		h.AppendChild(h, link)
+		heading := models.Heading{Level: h.Level, Text: text, Anchor: id}
+		if url := editURL(heading); url != "" {
+			edit := ast.NewLink()
+			edit.Destination = []byte(url)
+			edit.SetAttributeString("class", []byte(editClass))
+			edit.AppendChild(edit, ast.NewString([]byte("edit")))
+			h.AppendChild(h, ast.NewString([]byte(" ")))
+			h.AppendChild(h, edit)
+		}
+		headings = append(headings, heading)
+		return ast.WalkSkipChildren, nil
+	})
+	return headings
+}
+
+// Returns the text of the node without markup
+func plainText(n ast.Node, src []byte) string {
+	var b strings.Builder
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		switch c := c.(type) {
+		case *ast.Text:
+			b.Write(c.Segment.Value(src))
+			if c.SoftLineBreak() || c.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(c.Value)
+		case *ast.RawHTML:
+		default:
+			b.WriteString(plainText(c, src))
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Turns heading text into an id that stays the same as long as the text
+// does: lower case letters and digits, separated by hyphens
+func slug(text string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.M, r) || r == '_':
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		case unicode.IsSpace(r) || r == '-':
+			hyphen = true
+		}
+	}
+	if b.Len() == 0 {
+		return "section"
+	}
+	return b.String()
+}
+
+// Numbers repeated ids, e.g. the second "Usage" becomes usage-2
+func uniqueID(id string, used map[string]bool) string {
+	unique := id
+	for n := 2; used[unique]; n++ {
+		unique = fmt.Sprintf("%s-%d", id, n)
+	}
+	used[unique] = true
+	return unique
+}
+
+// Replaces [[TOC]] markers with the table of contents, or puts it above
+// the first heading if there are at least minHeadings
+func placeToc(out []byte, headings []models.Heading, minHeadings int) []byte {
+	if bytes.Contains(out, tocMarker) {
+		return bytes.ReplaceAll(out, tocMarker, tocHTML(headings))
+	}
+	if minHeadings <= 0 || len(headings) < minHeadings {
+		return out
+	}
+	loc := firstHeading.FindIndex(out)
+	if loc == nil {
+		return out
+	}
+	return append(append(append([]byte(nil), out[:loc[0]]...), tocHTML(headings)...), out[loc[0]:]...)
+}
+
+// Renders the headings as nested lists, deeper levels below the heading
+// before them
+func tocHTML(headings []models.Heading) []byte {
+	var b bytes.Buffer
+	b.WriteString(`<div class="` + tocClass + `">`)
+	var levels []int
+	for _, h := range headings {
+		for len(levels) > 0 && levels[len(levels)-1] > h.Level {
+			b.WriteString("</li></ul>")
+			levels = levels[:len(levels)-1]
+		}
+		if len(levels) > 0 && levels[len(levels)-1] == h.Level {
+			b.WriteString("</li>")
+		} else {
+			b.WriteString("<ul>")
+			levels = append(levels, h.Level)
+		}
+		fmt.Fprintf(&b, `<li><a href="#%s">%s</a>`, html.EscapeString(h.Anchor), html.EscapeString(h.Text))
+	}
+	for range levels {
+		b.WriteString("</li></ul>")
+	}
+	b.WriteString("</div>")
+	return b.Bytes()
+}
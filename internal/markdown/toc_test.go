@@ -0,0 +1,139 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dev-mackan/gowiki/pkg/models"
+)
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Install", "install"},
+		{"Restoring backups", "restoring-backups"},
+		{"  Spaces   and - hyphens  ", "spaces-and-hyphens"},
+		{"C++ & Go!", "c-go"},
+		{"snake_case", "snake_case"},
+		{"Version 2.0", "version-20"},
+		{"Größe straße", "größe-straße"},
+		{"Café", "café"},
+		{"日本語 ページ", "日本語-ページ"},
+		{"!!!", "section"},
+		{"", "section"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := slug(tt.text)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if !headingID.MatchString(got) {
+				t.Errorf("%q would be removed by the sanitiser", got)
+			}
+		})
+	}
+}
+
+func TestUniqueID(t *testing.T) {
+	used := make(map[string]bool)
+	var got []string
+	for _, id := range []string{"a", "a", "b", "a", "a-2"} {
+		got = append(got, uniqueID(id, used))
+	}
+	want := []string{"a", "a-2", "b", "a-3", "a-2-2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTocHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		headings []models.Heading
+		want     string
+	}{
+		{"none", nil, `<div class="toc"></div>`},
+		{"flat", []models.Heading{{Level: 1, Text: "A", Anchor: "a"}, {Level: 1, Text: "B", Anchor: "b"}},
+			`<div class="toc"><ul><li><a href="#a">A</a></li><li><a href="#b">B</a></li></ul></div>`},
+		{"nested", []models.Heading{{Level: 1, Text: "A", Anchor: "a"}, {Level: 2, Text: "B", Anchor: "b"}, {Level: 3, Text: "C", Anchor: "c"}, {Level: 1, Text: "D", Anchor: "d"}},
+			`<div class="toc"><ul><li><a href="#a">A</a><ul><li><a href="#b">B</a><ul><li><a href="#c">C</a></li></ul></li></ul></li><li><a href="#d">D</a></li></ul></div>`},
+		{"starts deeper", []models.Heading{{Level: 2, Text: "B", Anchor: "b"}, {Level: 1, Text: "A", Anchor: "a"}},
+			`<div class="toc"><ul><li><a href="#b">B</a></li></ul><ul><li><a href="#a">A</a></li></ul></div>`},
+		{"escaped", []models.Heading{{Level: 1, Text: "<b>&", Anchor: `a"b`}},
+			`<div class="toc"><ul><li><a href="#a&#34;b">&lt;b&gt;&amp;</a></li></ul></div>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(tocHTML(tt.headings)); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderHeadings(t *testing.T) {
+	tests := []struct {
+		name        string
+		minHeadings int
+		md          string
+		anchors     []string
+		toc         bool
+	}{
+		{"duplicates", 0, "# A\n## A\n# A\n", []string{"a", "a-2", "a-3"}, false},
+		{"non-ASCII", 0, "# Größe straße\n# 日本語\n", []string{"größe-straße", "日本語"}, false},
+		{"no text", 0, "# !!!\n# ???\n", []string{"section", "section-2"}, false},
+		{"marker", 0, "[[TOC]]\n\n# A\n", []string{"a"}, true},
+		{"marker below the threshold", 5, "[[TOC]]\n\n# A\n", []string{"a"}, true},
+		{"marker in text", 0, "See [[TOC]] here\n\n# A\n", []string{"a"}, false},
+		{"threshold reached", 2, "# A\n# B\n", []string{"a", "b"}, true},
+		{"below the threshold", 3, "# A\n# B\n", []string{"a", "b"}, false},
+		{"threshold disabled", 0, "# A\n# B\n# C\n# D\n", []string{"a", "b", "c", "d"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRenderer(&Config{TocMinHeadings: tt.minHeadings})
+			doc, err := r.Render([]byte(tt.md))
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := string(doc.HTML)
+			var anchors []string
+			for _, h := range doc.Headings {
+				anchors = append(anchors, h.Anchor)
+				// NOTE: The ids must survive the sanitiser, or the links go nowhere
+				if !strings.Contains(out, ` id="`+h.Anchor+`"`) {
+					t.Errorf("id %q missing from %s", h.Anchor, out)
+				}
+			}
+			if !reflect.DeepEqual(anchors, tt.anchors) {
+				t.Errorf("got anchors %q, want %q", anchors, tt.anchors)
+			}
+			if toc := strings.Contains(out, `<div class="toc">`); toc != tt.toc {
+				t.Errorf("got table of contents %v, want %v: %s", toc, tt.toc, out)
+			}
+			if strings.Contains(out, "<p>[[TOC]]</p>") {
+				t.Errorf("marker left in %s", out)
+			}
+		})
+	}
+}
+
+// The same text must always get the same id, so links to it keep working
+func TestHeadingIDsAreStable(t *testing.T) {
+	md := []byte("# Install\n## Linux\n# Usage\n## Linux\n")
+	first, err := NewRenderer(&Config{}).Render(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := NewRenderer(&Config{TocMinHeadings: 1, HighlightStyle: "monokai", Extensions: DefaultExtensions}).Render(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(first.Headings, again.Headings) {
+		t.Errorf("got %v and %v", first.Headings, again.Headings)
+	}
+}
@@ -1,3 +0,0 @@
-package messages
-
-type Empty struct{}
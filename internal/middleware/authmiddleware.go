@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/dev-mackan/gowiki/internal/auth"
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+)
+
+// Rejects requests without an accepted bearer token. The token is kept
+// in the request context, see auth.TokenFrom.
+func NewAuthMiddleware(a *auth.Authenticator) func(h http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := bearerToken(r)
+			if !a.Allow(token) {
+				log.Printf("Unauthenticated %s %s %s", r.RemoteAddr, r.Method, r.URL.Path)
+				w.Header().Set("WWW-Authenticate", `Bearer realm="gowiki"`)
+				wikierr.WriteProblem(w, r, wikierr.New(wikierr.Unauthorized, "A valid bearer token is required"))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(auth.WithToken(r.Context(), token)))
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dev-mackan/gowiki/internal/auth"
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		tokens []string
+		header string
+		status int
+	}{
+		{"disabled", nil, "", 200},
+		{"accepted token", []string{"secret"}, "Bearer secret", 200},
+		{"missing token", []string{"secret"}, "", 401},
+		{"wrong token", []string{"secret"}, "Bearer wrong", 401},
+		{"other scheme", []string{"secret"}, "Basic secret", 401},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var token string
+			h := NewAuthMiddleware(auth.NewAuthenticator(tt.tokens))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				token = auth.TokenFrom(r.Context())
+			}))
+			r := httptest.NewRequest("GET", "/api/v2/pages", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("got status %d, want %d", w.Code, tt.status)
+			}
+			if tt.status == 200 {
+				if tt.tokens != nil && token != "secret" {
+					t.Errorf("got token %q in the context", token)
+				}
+				return
+			}
+			if got := w.Header().Get("WWW-Authenticate"); got != `Bearer realm="gowiki"` {
+				t.Errorf("got WWW-Authenticate %q", got)
+			}
+			assertProblem(t, w, wikierr.Unauthorized)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
+	"net/http"
+)
+
+const (
+	SessionCookieName = "gowiki_session"
+	CSRFFormField     = "csrf_token"
+	CSRFHeader        = "X-CSRF-Token"
+)
+
+type csrfContextKey struct{}
+
+// Returns the CSRF token of the session making the request.
+// Empty if the request did not pass through the CSRF middleware.
+func CSRFToken(r *http.Request) string {
+	token, _ := r.Context().Value(csrfContextKey{}).(string)
+	return token
+}
+
+// Returns the session id of the request, or an empty string if the request
+// has no valid session cookie.
+func SessionID(r *http.Request) string {
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil || !validSessionID(cookie.Value) {
+		return ""
+	}
+	return cookie.Value
+}
+
+// Identifies the session of the request to other services, such as the
+// owner of drafts, without giving them the session id itself.
+// Empty if the request has no valid session cookie.
+func SessionOwner(r *http.Request) string {
+	sessionId := SessionID(r)
+	if sessionId == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte("owner:" + sessionId))
+	return hex.EncodeToString(sum[:])
+}
+
+// Ties a CSRF token to a session cookie and requires the token on every
+// request with an unsafe method. The token is derived from the session id
+// with key, so no server side session storage is needed.
+func NewCSRFMiddleware(key []byte, secureCookie bool) func(h http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sessionId := SessionID(r)
+			if !isSafeMethod(r.Method) {
+				if sessionId == "" || !validCSRFToken(key, sessionId, requestCSRFToken(r)) {
+					log.Printf("CSRF check failed: %s %s %s", r.Method, r.RemoteAddr, r.URL.Path)
+					http.Error(w, "Invalid CSRF token", http.StatusForbidden)
+					return
+				}
+			}
+			if sessionId == "" {
+				sessionId = newSessionID()
+				http.SetCookie(w, &http.Cookie{
+					Name:     SessionCookieName,
+					Value:    sessionId,
+					Path:     "/",
+					HttpOnly: true,
+					Secure:   secureCookie,
+					SameSite: http.SameSiteLaxMode,
+				})
+				// NOTE: Later lookups of the session in this request should see the new id
+				r.AddCookie(&http.Cookie{Name: SessionCookieName, Value: sessionId})
+			}
+			ctx := context.WithValue(r.Context(), csrfContextKey{}, csrfTokenFor(key, sessionId))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func requestCSRFToken(r *http.Request) string {
+	if token := r.Header.Get(CSRFHeader); token != "" {
+		return token
+	}
+	return r.FormValue(CSRFFormField)
+}
+
+func validCSRFToken(key []byte, sessionId string, token string) bool {
+	expected := csrfTokenFor(key, sessionId)
+	return hmac.Equal([]byte(expected), []byte(token))
+}
+
+func csrfTokenFor(key []byte, sessionId string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(sessionId))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newSessionID() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func validSessionID(id string) bool {
+	b, err := base64.RawURLEncoding.DecodeString(id)
+	return err == nil && len(b) == 32
+}
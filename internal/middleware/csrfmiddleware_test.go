@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCSRFMiddleware(t *testing.T) {
+	key := []byte("key")
+	sessionId := newSessionID()
+	token := csrfTokenFor(key, sessionId)
+	tests := []struct {
+		name    string
+		method  string
+		session string
+		header  string
+		form    string
+		status  int
+	}{
+		{"safe method without session", "GET", "", "", "", 200},
+		{"header token", "POST", sessionId, token, "", 200},
+		{"form token", "POST", sessionId, "", token, 200},
+		{"missing token", "POST", sessionId, "", "", 403},
+		{"wrong token", "POST", sessionId, "wrong", "", 403},
+		{"token of another session", "POST", newSessionID(), token, "", 403},
+		{"no session", "POST", "", token, "", 403},
+		{"other key", "DELETE", sessionId, csrfTokenFor([]byte("other"), sessionId), "", 403},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			h := NewCSRFMiddleware(key, false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = CSRFToken(r)
+			}))
+			form := url.Values{}
+			if tt.form != "" {
+				form.Set(CSRFFormField, tt.form)
+			}
+			r := httptest.NewRequest(tt.method, "/", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.header != "" {
+				r.Header.Set(CSRFHeader, tt.header)
+			}
+			if tt.session != "" {
+				r.AddCookie(&http.Cookie{Name: SessionCookieName, Value: tt.session})
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("got status %d, want %d", w.Code, tt.status)
+			}
+			if tt.status != 200 {
+				return
+			}
+			if tt.session == "" {
+				cookies := w.Result().Cookies()
+				if len(cookies) != 1 || !validSessionID(cookies[0].Value) {
+					t.Fatalf("got cookies %v, want a new session", cookies)
+				}
+				// NOTE: The token handed to the page must belong to the new session
+				if seen != csrfTokenFor(key, cookies[0].Value) {
+					t.Errorf("token %q is not for the new session", seen)
+				}
+			} else if seen != token {
+				t.Errorf("got token %q, want %q", seen, token)
+			}
+		})
+	}
+}
+
+func TestSessionOwner(t *testing.T) {
+	sessionId := newSessionID()
+	tests := []struct {
+		name   string
+		cookie string
+		empty  bool
+	}{
+		{"valid session", sessionId, false},
+		{"no session", "", true},
+		{"invalid session", "not-a-session", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: SessionCookieName, Value: tt.cookie})
+			}
+			owner := SessionOwner(r)
+			if tt.empty {
+				if owner != "" {
+					t.Fatalf("got owner %q, want none", owner)
+				}
+				return
+			}
+			if owner == "" || strings.Contains(owner, sessionId) {
+				t.Fatalf("owner %q must be set and not contain the session id", owner)
+			}
+			if again := SessionOwner(r); again != owner {
+				t.Errorf("owner changed from %q to %q", owner, again)
+			}
+		})
+	}
+}
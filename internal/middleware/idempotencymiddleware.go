@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+	"github.com/dev-mackan/gowiki/pkg/models"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// Set on responses that were replayed for a retried request
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+const (
+	maxIdempotencyKeyLen = 255
+	// Larger responses are not stored, a retry runs the request again
+	maxStoredResponseLen = 1 << 20
+)
+
+type IdempotencyStore interface {
+	GetStoredResponse(ctx context.Context, key string, since time.Time) (*models.StoredResponse, error)
+	SaveStoredResponse(ctx context.Context, res *models.StoredResponse) error
+	DeleteStoredResponses(ctx context.Context, before time.Time) error
+}
+
+// Replays the stored response when a mutating request is retried with the
+// same Idempotency-Key within the window. Keys are scoped to the bearer token.
+// Server errors are not stored, so a retry after one runs the request again.
+func NewIdempotencyMiddleware(store IdempotencyStore, window time.Duration) func(h http.Handler) http.Handler {
+	var mu sync.Mutex
+	inFlight := make(map[string]bool)
+	var lastSweep time.Time
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" || isSafeMethod(r.Method) || window <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLen {
+				wikierr.WriteProblem(w, r, wikierr.New(wikierr.BadRequest, "Idempotency-Key is too long"))
+				return
+			}
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				wikierr.WriteProblem(w, r, wikierr.Wrap(err, wikierr.BadRequest, "Could not read the request body"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			key = idempotencyScope(r) + ":" + key
+			fingerprint := requestFingerprint(r, body)
+
+			mu.Lock()
+			if inFlight[key] {
+				mu.Unlock()
+				wikierr.WriteProblem(w, r, wikierr.New(wikierr.RequestInProgress, "A request with this Idempotency-Key is in progress"))
+				return
+			}
+			inFlight[key] = true
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				delete(inFlight, key)
+				mu.Unlock()
+			}()
+
+			ctx := r.Context()
+			now := time.Now()
+			stored, err := store.GetStoredResponse(ctx, key, now.Add(-window))
+			if err != nil {
+				log.Println(err)
+				wikierr.WriteProblem(w, r, wikierr.From(err))
+				return
+			}
+			if stored != nil {
+				if stored.Fingerprint != fingerprint {
+					wikierr.WriteProblem(w, r, wikierr.New(wikierr.ValidationFailed, "Idempotency-Key was used for a different request"))
+					return
+				}
+				replay(w, stored)
+				return
+			}
+
+			rec := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			if rec.status == 0 {
+				// NOTE: The handler wrote nothing, net/http sends a 200
+				rec.WriteHeader(http.StatusOK)
+			}
+			if rec.status >= 500 || rec.tooLarge {
+				return
+			}
+			err = store.SaveStoredResponse(ctx, &models.StoredResponse{
+				Key:         key,
+				Fingerprint: fingerprint,
+				Status:      rec.status,
+				Header:      rec.header,
+				Body:        rec.body.Bytes(),
+				CreatedAt:   now,
+			})
+			if err != nil {
+				// NOTE: The request succeeded, only a retry of it is unprotected
+				log.Println(err)
+			}
+
+			mu.Lock()
+			sweep := now.Sub(lastSweep) > time.Minute
+			if sweep {
+				lastSweep = now
+			}
+			mu.Unlock()
+			if sweep {
+				if err := store.DeleteStoredResponses(ctx, now.Add(-window)); err != nil {
+					log.Println(err)
+				}
+			}
+		})
+	}
+}
+
+func replay(w http.ResponseWriter, stored *models.StoredResponse) {
+	for name, vals := range stored.Header {
+		w.Header()[name] = vals
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(stored.Status)
+	w.Write(stored.Body)
+}
+
+// Keys of different clients must not collide
+func idempotencyScope(r *http.Request) string {
+	if token := bearerToken(r); token != "" {
+		sum := sha256.Sum256([]byte(token))
+		return "token:" + hex.EncodeToString(sum[:])
+	}
+	return "anonymous"
+}
+
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Passes the response through while keeping a copy of it
+type responseRecorder struct {
+	http.ResponseWriter
+	status   int
+	header   http.Header
+	body     bytes.Buffer
+	tooLarge bool
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status != 0 {
+		return
+	}
+	rec.status = status
+	rec.header = rec.Header().Clone()
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.WriteHeader(http.StatusOK)
+	}
+	if rec.body.Len()+len(b) > maxStoredResponseLen {
+		rec.tooLarge = true
+	} else if !rec.tooLarge {
+		rec.body.Write(b)
+	}
+	return rec.ResponseWriter.Write(b)
+}
@@ -0,0 +1,210 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+	"github.com/dev-mackan/gowiki/pkg/models"
+)
+
+type memIdempotencyStore struct {
+	mu        sync.Mutex
+	responses map[string]*models.StoredResponse
+	err       error
+}
+
+func newMemIdempotencyStore() *memIdempotencyStore {
+	return &memIdempotencyStore{responses: make(map[string]*models.StoredResponse)}
+}
+
+func (s *memIdempotencyStore) GetStoredResponse(ctx context.Context, key string, since time.Time) (*models.StoredResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	res, ok := s.responses[key]
+	if !ok || res.CreatedAt.Before(since) {
+		return nil, nil
+	}
+	return res, nil
+}
+
+func (s *memIdempotencyStore) SaveStoredResponse(ctx context.Context, res *models.StoredResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[res.Key] = res
+	return nil
+}
+
+func (s *memIdempotencyStore) DeleteStoredResponses(ctx context.Context, before time.Time) error {
+	return nil
+}
+
+type idempotentRequest struct {
+	method string
+	path   string
+	key    string
+	token  string
+	body   string
+	// Expected status, problem code if the middleware replied itself and
+	// whether the response was replayed
+	want     int
+	code     wikierr.Code
+	replayed bool
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		requests []idempotentRequest
+		// Times the handler should run
+		runs int
+	}{
+		{"retry is replayed", http.StatusCreated, []idempotentRequest{
+			{method: "POST", path: "/api/v1/pages", key: "a", body: "x", want: 201},
+			{method: "POST", path: "/api/v1/pages", key: "a", body: "x", want: 201, replayed: true},
+		}, 1},
+		{"requests without a key run", http.StatusCreated, []idempotentRequest{
+			{method: "POST", path: "/api/v1/pages", body: "x", want: 201},
+			{method: "POST", path: "/api/v1/pages", body: "x", want: 201},
+		}, 2},
+		{"safe methods are not stored", http.StatusOK, []idempotentRequest{
+			{method: "GET", path: "/api/v1/pages", key: "a", want: 200},
+			{method: "GET", path: "/api/v1/pages", key: "a", want: 200},
+		}, 2},
+		{"server errors are not stored", http.StatusInternalServerError, []idempotentRequest{
+			{method: "POST", path: "/api/v1/pages", key: "a", body: "x", want: 500},
+			{method: "POST", path: "/api/v1/pages", key: "a", body: "x", want: 500},
+		}, 2},
+		{"keys are scoped to tokens", http.StatusCreated, []idempotentRequest{
+			{method: "POST", path: "/api/v1/pages", key: "a", token: "t1", body: "x", want: 201},
+			{method: "POST", path: "/api/v1/pages", key: "a", token: "t2", body: "x", want: 201},
+		}, 2},
+		{"key reused for another body", http.StatusCreated, []idempotentRequest{
+			{method: "POST", path: "/api/v1/pages", key: "a", body: "x", want: 201},
+			{method: "POST", path: "/api/v1/pages", key: "a", body: "y", want: 422, code: wikierr.ValidationFailed},
+		}, 1},
+		{"key reused for another path", http.StatusNoContent, []idempotentRequest{
+			{method: "DELETE", path: "/api/v1/pages/1", key: "a", want: 204},
+			{method: "DELETE", path: "/api/v1/pages/2", key: "a", want: 422, code: wikierr.ValidationFailed},
+		}, 1},
+		{"key too long", http.StatusCreated, []idempotentRequest{
+			{method: "POST", path: "/api/v1/pages", key: strings.Repeat("a", maxIdempotencyKeyLen+1), want: 400, code: wikierr.BadRequest},
+		}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs := 0
+			h := NewIdempotencyMiddleware(newMemIdempotencyStore(), time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				runs++
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				fmt.Fprintf(w, `{"run":%d}`, runs)
+			}))
+			var first string
+			for i, rq := range tt.requests {
+				r := httptest.NewRequest(rq.method, rq.path, strings.NewReader(rq.body))
+				if rq.key != "" {
+					r.Header.Set(IdempotencyKeyHeader, rq.key)
+				}
+				if rq.token != "" {
+					r.Header.Set("Authorization", "Bearer "+rq.token)
+				}
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, r)
+				if w.Code != rq.want {
+					t.Fatalf("request %d: got status %d, want %d", i, w.Code, rq.want)
+				}
+				if rq.code != "" {
+					assertProblem(t, w, rq.code)
+				}
+				if replayed := w.Header().Get(IdempotentReplayedHeader) == "true"; replayed != rq.replayed {
+					t.Fatalf("request %d: got replayed %v, want %v", i, replayed, rq.replayed)
+				}
+				if i == 0 {
+					first = w.Body.String()
+				} else if rq.replayed && w.Body.String() != first {
+					t.Fatalf("request %d: replayed %q, want %q", i, w.Body.String(), first)
+				}
+			}
+			if runs != tt.runs {
+				t.Errorf("handler ran %d times, want %d", runs, tt.runs)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareInProgress(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := NewIdempotencyMiddleware(newMemIdempotencyStore(), time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+	newRequest := func() *http.Request {
+		r := httptest.NewRequest("POST", "/api/v1/pages", strings.NewReader("x"))
+		r.Header.Set(IdempotencyKeyHeader, "a")
+		return r
+	}
+	done := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, newRequest())
+		done <- w.Code
+	}()
+	<-started
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newRequest())
+	if w.Code != http.StatusConflict {
+		t.Fatalf("got status %d, want 409", w.Code)
+	}
+	assertProblem(t, w, wikierr.RequestInProgress)
+	close(release)
+	if code := <-done; code != http.StatusCreated {
+		t.Errorf("original request got status %d, want 201", code)
+	}
+}
+
+func TestIdempotencyMiddlewareStoreError(t *testing.T) {
+	store := newMemIdempotencyStore()
+	store.err = errors.New("no such table: IdempotencyKey")
+	h := NewIdempotencyMiddleware(store, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	r := httptest.NewRequest("POST", "/api/v1/pages", nil)
+	r.Header.Set(IdempotencyKeyHeader, "a")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("got status %d, want 500", w.Code)
+	}
+	problem := assertProblem(t, w, wikierr.Internal)
+	if strings.Contains(problem.Detail, "IdempotencyKey") {
+		t.Errorf("detail %q leaks the underlying error", problem.Detail)
+	}
+}
+
+func assertProblem(t *testing.T, w *httptest.ResponseRecorder, code wikierr.Code) wikierr.Problem {
+	t.Helper()
+	if got := w.Header().Get("Content-Type"); got != "application/problem+json" {
+		t.Fatalf("got Content-Type %q, want application/problem+json", got)
+	}
+	var problem wikierr.Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Code != code || problem.Status != w.Code {
+		t.Fatalf("got problem %+v, want code %s", problem, code)
+	}
+	return problem
+}
@@ -0,0 +1,228 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dev-mackan/gowiki/internal/auth"
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+	"github.com/dev-mackan/gowiki/pkg/utils"
+)
+
+type RateLimitConfig struct {
+	// Requests per second and burst size for safe methods
+	ReadRate  float64
+	ReadBurst int
+	// Requests per second and burst size for unsafe methods
+	WriteRate  float64
+	WriteBurst int
+	// Paths whose unsafe requests save nothing, such as previews.
+	// They count as reads.
+	ReadPaths []string
+	// X-Forwarded-For is only trusted when the request comes from one of these CIDRs
+	TrustedProxies []string
+}
+
+func DefaultRateLimitConfig() *RateLimitConfig {
+	proxies := utils.EnvList("GOWIKI_TRUSTED_PROXIES")
+	if proxies == nil {
+		// NOTE: The web server forwards the IPs of its clients to the API
+		proxies = []string{"127.0.0.1", "::1"}
+	}
+	return &RateLimitConfig{
+		ReadRate:       utils.EnvFloat("GOWIKI_RATE_READ", 20),
+		ReadBurst:      utils.EnvInt("GOWIKI_RATE_READ_BURST", 40),
+		WriteRate:      utils.EnvFloat("GOWIKI_RATE_WRITE", 1),
+		WriteBurst:     utils.EnvInt("GOWIKI_RATE_WRITE_BURST", 10),
+		TrustedProxies: proxies,
+	}
+}
+
+type clientIPContextKey struct{}
+
+// Returns the IP of the client making the request, as seen by the rate
+// limit middleware. Empty if the request did not pass through it.
+func ClientIP(r *http.Request) string {
+	ip, _ := r.Context().Value(clientIPContextKey{}).(string)
+	return ip
+}
+
+// Limits requests per client IP and, when a bearer token is present,
+// per token. Reads and writes are counted against separate budgets.
+// Requests forwarded by a trusted proxy are only limited per client IP,
+// as the token belongs to the proxy and is shared by all of its clients.
+func NewRateLimitMiddleware(cfg *RateLimitConfig) func(h http.Handler) http.Handler {
+	proxies := parseCIDRs(cfg.TrustedProxies)
+	readPaths := make(map[string]bool)
+	for _, path := range cfg.ReadPaths {
+		readPaths[path] = true
+	}
+	reads := newBucketStore(cfg.ReadRate, cfg.ReadBurst)
+	writes := newBucketStore(cfg.WriteRate, cfg.WriteBurst)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			store := reads
+			if !isSafeMethod(r.Method) && !readPaths[r.URL.Path] {
+				store = writes
+			}
+			ip, forwarded := clientIP(r, proxies)
+			keys := []string{"ip:" + ip}
+			if token := bearerToken(r); token != "" && !forwarded {
+				sum := sha256.Sum256([]byte(token))
+				keys = append(keys, "token:"+hex.EncodeToString(sum[:]))
+			}
+			if wait, ok := store.take(keys...); !ok {
+				log.Printf("Rate limited %s %s %s", strings.Join(keys, " "), r.Method, r.URL.Path)
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				wikierr.WriteProblem(w, r, wikierr.New(wikierr.RateLimited, "Too many requests"))
+				return
+			}
+			ctx := context.WithValue(r.Context(), clientIPContextKey{}, ip)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type bucketStore struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func newBucketStore(rate float64, burst int) *bucketStore {
+	return &bucketStore{
+		rate:      rate,
+		burst:     float64(burst),
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Takes a token from the bucket of every key, or from none of them if any
+// bucket is empty. Returns how long to wait for the next token then.
+func (s *bucketStore) take(keys ...string) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.sweep(now)
+	var wait time.Duration
+	empty := false
+	buckets := make([]*bucket, len(keys))
+	for i, key := range keys {
+		b, ok := s.buckets[key]
+		if !ok {
+			b = &bucket{tokens: s.burst, last: now}
+			s.buckets[key] = b
+		}
+		b.tokens = math.Min(s.burst, b.tokens+now.Sub(b.last).Seconds()*s.rate)
+		b.last = now
+		if b.tokens < 1 {
+			empty = true
+			if s.rate <= 0 {
+				wait = max(wait, time.Minute)
+			} else {
+				wait = max(wait, time.Duration((1-b.tokens)/s.rate*float64(time.Second)))
+			}
+		}
+		buckets[i] = b
+	}
+	if empty {
+		return wait, false
+	}
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return 0, true
+}
+
+// Drops buckets that have refilled, they behave the same as new ones
+func (s *bucketStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*s.rate >= s.burst {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func bearerToken(r *http.Request) string {
+	return auth.BearerToken(r.Header.Get("Authorization"))
+}
+
+// Returns the IP of the client. X-Forwarded-For is walked from the right
+// and the first address that is not a trusted proxy is used.
+// forwarded is true when the IP was taken from X-Forwarded-For.
+func clientIP(r *http.Request, proxies []*net.IPNet) (ip string, forwarded bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(host, proxies) {
+		return host, false
+	}
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		forwarded = true
+		if !isTrustedProxy(hop, proxies) {
+			return hop, true
+		}
+		host = hop
+	}
+	return host, forwarded
+}
+
+func isTrustedProxy(addr string, proxies []*net.IPNet) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range proxies {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseCIDRs(cidrs []string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, c := range cidrs {
+		if !strings.Contains(c, "/") {
+			if strings.Contains(c, ":") {
+				c += "/128"
+			} else {
+				c += "/32"
+			}
+		}
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			log.Printf("Ignoring invalid trusted proxy %q: %v", c, err)
+			continue
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
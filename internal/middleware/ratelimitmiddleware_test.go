@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+)
+
+type rateLimitRequest struct {
+	method string
+	path   string
+	remote string
+	xff    string
+	token  string
+	want   int
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	cfg := &RateLimitConfig{
+		ReadRate:       0,
+		ReadBurst:      2,
+		WriteRate:      0,
+		WriteBurst:     1,
+		ReadPaths:      []string{"/api/v1/render"},
+		TrustedProxies: []string{"127.0.0.1"},
+	}
+	tests := []struct {
+		name     string
+		requests []rateLimitRequest
+	}{
+		{"reads have their own budget", []rateLimitRequest{
+			{method: "POST", path: "/api/v1/pages", remote: "10.0.0.1:1", want: 200},
+			{method: "GET", path: "/api/v1/pages", remote: "10.0.0.1:1", want: 200},
+			{method: "GET", path: "/api/v1/pages", remote: "10.0.0.1:1", want: 200},
+			{method: "GET", path: "/api/v1/pages", remote: "10.0.0.1:1", want: 429},
+		}},
+		{"read paths count as reads", []rateLimitRequest{
+			{method: "POST", path: "/api/v1/render", remote: "10.0.0.1:1", want: 200},
+			{method: "POST", path: "/api/v1/render", remote: "10.0.0.1:1", want: 200},
+			{method: "PUT", path: "/api/v1/pages/1", remote: "10.0.0.1:1", want: 200},
+			{method: "PUT", path: "/api/v1/pages/1", remote: "10.0.0.1:1", want: 429},
+		}},
+		{"clients have their own budget", []rateLimitRequest{
+			{method: "POST", path: "/api/v1/pages", remote: "10.0.0.1:1", want: 200},
+			{method: "POST", path: "/api/v1/pages", remote: "10.0.0.1:1", want: 429},
+			{method: "POST", path: "/api/v1/pages", remote: "10.0.0.2:1", want: 200},
+		}},
+		{"forwarded clients have their own budget", []rateLimitRequest{
+			{method: "POST", path: "/api/v1/pages", remote: "127.0.0.1:1", xff: "10.0.0.1", token: "web", want: 200},
+			{method: "POST", path: "/api/v1/pages", remote: "127.0.0.1:1", xff: "10.0.0.2", token: "web", want: 200},
+			{method: "POST", path: "/api/v1/pages", remote: "127.0.0.1:1", xff: "10.0.0.1", token: "web", want: 429},
+		}},
+		{"untrusted forwarding is ignored", []rateLimitRequest{
+			{method: "POST", path: "/api/v1/pages", remote: "10.0.0.1:1", xff: "10.0.0.2", want: 200},
+			{method: "POST", path: "/api/v1/pages", remote: "10.0.0.1:1", xff: "10.0.0.3", want: 429},
+		}},
+		{"tokens are limited across IPs", []rateLimitRequest{
+			{method: "POST", path: "/api/v1/pages", remote: "10.0.0.1:1", token: "a", want: 200},
+			{method: "POST", path: "/api/v1/pages", remote: "10.0.0.2:1", token: "a", want: 429},
+		}},
+		{"a limited request takes from no bucket", []rateLimitRequest{
+			{method: "POST", path: "/api/v1/pages", remote: "10.0.0.1:1", token: "a", want: 200},
+			// The token bucket is empty, the IP bucket of 10.0.0.2 must stay full
+			{method: "POST", path: "/api/v1/pages", remote: "10.0.0.2:1", token: "a", want: 429},
+			{method: "POST", path: "/api/v1/pages", remote: "10.0.0.2:1", token: "b", want: 200},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewRateLimitMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			for i, rq := range tt.requests {
+				r := httptest.NewRequest(rq.method, rq.path, nil)
+				r.RemoteAddr = rq.remote
+				if rq.xff != "" {
+					r.Header.Set("X-Forwarded-For", rq.xff)
+				}
+				if rq.token != "" {
+					r.Header.Set("Authorization", "Bearer "+rq.token)
+				}
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, r)
+				if w.Code != rq.want {
+					t.Fatalf("request %d: got status %d, want %d", i, w.Code, rq.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddlewareProblem(t *testing.T) {
+	cfg := &RateLimitConfig{WriteRate: 0.5, WriteBurst: 0}
+	h := NewRateLimitMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/pages", nil))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("got status %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/problem+json" {
+		t.Errorf("got Content-Type %q", got)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("got Retry-After %q, want 2", got)
+	}
+	var problem wikierr.Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Code != wikierr.RateLimited || problem.Status != http.StatusTooManyRequests {
+		t.Errorf("got problem %+v", problem)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies := parseCIDRs([]string{"127.0.0.1", "192.168.0.0/16"})
+	tests := []struct {
+		name      string
+		remote    string
+		xff       string
+		want      string
+		forwarded bool
+	}{
+		{"direct", "10.0.0.1:1234", "", "10.0.0.1", false},
+		{"untrusted proxy", "10.0.0.1:1234", "10.0.0.2", "10.0.0.1", false},
+		{"trusted proxy", "127.0.0.1:1234", "10.0.0.2", "10.0.0.2", true},
+		{"proxy chain", "127.0.0.1:1234", "10.0.0.9, 10.0.0.2, 192.168.1.1", "10.0.0.2", true},
+		{"trusted proxy without header", "127.0.0.1:1234", "", "127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remote
+			if tt.xff != "" {
+				r.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			ip, forwarded := clientIP(r, proxies)
+			if ip != tt.want || forwarded != tt.forwarded {
+				t.Errorf("got %q, %v, want %q, %v", ip, forwarded, tt.want, tt.forwarded)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Package namespaces splits page titles such as "Template:Infobox" into a
+// namespace and a name, and decides who may edit the pages of a namespace.
+package namespaces
+
+import (
+	"log"
+	"strings"
+
+	"github.com/dev-mackan/gowiki/internal/auth"
+	"github.com/dev-mackan/gowiki/pkg/models"
+	"github.com/dev-mackan/gowiki/pkg/utils"
+)
+
+// Namespaces every wiki has, more can be added with GOWIKI_NAMESPACES
+var BuiltIn = []string{"Help", "Project", "Template", "User"}
+
+type Namespace struct {
+	models.Namespace
+	// nil when anyone who may use the API may edit
+	editors *auth.Authenticator
+}
+
+// Reports whether a request with the token may create, edit, rename or
+// delete pages in the namespace
+func (ns *Namespace) CanEdit(token string) bool {
+	return ns.editors == nil || ns.editors.Allow(token)
+}
+
+// Returns the full title of a page named name in the namespace
+func (ns *Namespace) Title(name string) string {
+	if ns.Name == "" {
+		return name
+	}
+	return ns.Name + ":" + name
+}
+
+type Namespaces struct {
+	list []*Namespace
+	// By the case folded name
+	byKey map[string]*Namespace
+}
+
+// Reads the extra namespaces from GOWIKI_NAMESPACES and the editors of each
+// namespace from GOWIKI_NAMESPACE_<NAME>_EDITORS, e.g.
+// GOWIKI_NAMESPACE_TEMPLATE_EDITORS
+func Default() *Namespaces {
+	names := append(append([]string(nil), BuiltIn...), utils.EnvList("GOWIKI_NAMESPACES")...)
+	editors := make(map[string][]string, len(names))
+	for _, name := range names {
+		editors[name] = utils.EnvList("GOWIKI_NAMESPACE_" + strings.ToUpper(name) + "_EDITORS")
+	}
+	return New(names, editors)
+}
+
+// The main namespace is always included. editors maps namespace names to
+// the tokens allowed to edit their pages, namespaces without tokens can be
+// edited by anyone who may use the API.
+func New(names []string, editors map[string][]string) *Namespaces {
+	n := &Namespaces{byKey: make(map[string]*Namespace)}
+	n.add("", editors[""])
+	for _, name := range names {
+		tokens := editors[name]
+		name = utils.SanitizeTitle(name)
+		if name == "" || strings.Contains(name, ":") {
+			log.Printf("Ignoring invalid namespace %q", name)
+			continue
+		}
+		n.add(name, tokens)
+	}
+	return n
+}
+
+func (n *Namespaces) add(name string, tokens []string) {
+	key := utils.TitleKey(name)
+	if _, ok := n.byKey[key]; ok {
+		return
+	}
+	ns := &Namespace{Namespace: models.Namespace{Name: name, Protected: len(tokens) > 0}}
+	if len(tokens) > 0 {
+		ns.editors = auth.NewAuthenticator(tokens)
+	}
+	n.list = append(n.list, ns)
+	n.byKey[key] = ns
+}
+
+// Returns the namespaces in the order they were configured, starting
+// with the main namespace
+func (n *Namespaces) All() []models.Namespace {
+	all := make([]models.Namespace, len(n.list))
+	for i, ns := range n.list {
+		all[i] = ns.Namespace
+	}
+	return all
+}
+
+// Looks a namespace up by name, ignoring case
+func (n *Namespaces) Get(name string) (*Namespace, bool) {
+	ns, ok := n.byKey[utils.TitleKey(name)]
+	return ns, ok
+}
+
+// Splits a sanitised title into its namespace and the name within it.
+// The prefix is matched ignoring case, titles without a known prefix are
+// in the main namespace, e.g. "Foo:Bar" is the page "Foo:Bar" of the main
+// namespace unless Foo is configured.
+func (n *Namespaces) Parse(title string) (*Namespace, string) {
+	if prefix, name, ok := strings.Cut(title, ":"); ok {
+		if ns, ok := n.Get(prefix); ok && ns.Name != "" {
+			return ns, strings.TrimLeft(name, "_")
+		}
+	}
+	return n.list[0], title
+}
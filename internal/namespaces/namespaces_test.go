@@ -0,0 +1,74 @@
+package namespaces
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	n := New(BuiltIn, nil)
+	tests := []struct {
+		title     string
+		namespace string
+		name      string
+	}{
+		{"Home", "", "Home"},
+		{"Template:Infobox", "Template", "Infobox"},
+		{"template:Infobox", "Template", "Infobox"},
+		{"Template:_Infobox", "Template", "Infobox"},
+		{"Foo:Bar", "", "Foo:Bar"},
+		{":Home", "", ":Home"},
+		{"User:Alice/Notes", "User", "Alice/Notes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			ns, name := n.Parse(tt.title)
+			if ns.Name != tt.namespace || name != tt.name {
+				t.Errorf("got %q, %q, want %q, %q", ns.Name, name, tt.namespace, tt.name)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	n := New([]string{"Help", "help", "Team Docs", "Bad:Name", "", "Ops"}, map[string][]string{"Ops": {"secret"}})
+	var names []string
+	for _, ns := range n.All() {
+		names = append(names, ns.Name)
+	}
+	want := []string{"", "Help", "Team_Docs", "Ops"}
+	if len(names) != len(want) {
+		t.Fatalf("got namespaces %q, want %q", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("got namespaces %q, want %q", names, want)
+		}
+	}
+	if ns, ok := n.Get("team_docs"); !ok || ns.Name != "Team_Docs" {
+		t.Errorf("got %v, %v", ns, ok)
+	}
+}
+
+func TestCanEdit(t *testing.T) {
+	n := New([]string{"Help", "Ops"}, map[string][]string{"Ops": {"secret"}})
+	tests := []struct {
+		namespace string
+		token     string
+		want      bool
+	}{
+		{"", "", true},
+		{"Help", "", true},
+		{"Ops", "", false},
+		{"Ops", "wrong", false},
+		{"Ops", "secret", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.namespace+"/"+tt.token, func(t *testing.T) {
+			ns, _ := n.Get(tt.namespace)
+			if got := ns.CanEdit(tt.token); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if ns.Protected != (tt.namespace == "Ops") {
+				t.Errorf("got protected %v", ns.Protected)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+// Package rendercache keeps the HTML rendered from revisions. The content of
+// a revision never changes, but the templates it calls can, so entries
+// remember the templates they were rendered with and are only used while
+// those are unchanged.
+package rendercache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dev-mackan/gowiki/pkg/models"
+	"github.com/dev-mackan/gowiki/pkg/utils"
+)
+
+type Config struct {
+	// Entries kept in memory, the least recently used are dropped first.
+	// 0 disables the cache.
+	Size int
+	// Entries are also written to this directory if set, so they survive
+	// restarts and outlive the memory
+	Dir string
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		Size: utils.EnvInt("GOWIKI_RENDER_CACHE_SIZE", 1000),
+		Dir:  os.Getenv("GOWIKI_RENDER_CACHE_DIR"),
+	}
+}
+
+type Key struct {
+	RevId uint
+	// The version of the renderer, see markdown.Renderer.Version
+	Version string
+	// Tells renderings of the same revision apart, e.g. with and without
+	// edit links
+	Variant string
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%d/%s/%s", k.RevId, k.Version, k.Variant)
+}
+
+type Entry struct {
+	HTML template.HTML `json:"html"`
+	// The templates used, with the latest revisions they had
+	Templates []models.Page `json:"templates"`
+	// The titles of called templates that did not exist
+	Missing []string `json:"missing"`
+}
+
+type item struct {
+	key   Key
+	entry *Entry
+}
+
+// An LRU cache of entries, safe for concurrent use
+type Cache struct {
+	size int
+	dir  string
+	mu   sync.Mutex
+	// Most recently used first
+	order  *list.List
+	items  map[Key]*list.Element
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func New(config *Config) *Cache {
+	if config.Dir != "" {
+		if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+			log.Fatal(err)
+		}
+	}
+	return &Cache{
+		size:  config.Size,
+		dir:   config.Dir,
+		order: list.New(),
+		items: make(map[Key]*list.Element),
+	}
+}
+
+// Returns the entry for the key if there is one and valid accepts it.
+// Entries that are not valid are removed. valid is called without holding
+// the lock, so it may be slow.
+func (c *Cache) Get(key Key, valid func(*Entry) bool) (*Entry, bool) {
+	entry := c.lookup(key)
+	if entry != nil && !valid(entry) {
+		c.remove(key)
+		entry = nil
+	}
+	if entry == nil {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return entry, true
+}
+
+func (c *Cache) Put(key Key, entry *Entry) {
+	if c.size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	c.add(key, entry)
+	c.mu.Unlock()
+	c.write(key, entry)
+}
+
+// Returns the number of lookups that found a valid entry and that did not
+func (c *Cache) Stats() (hits uint64, misses uint64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+func (c *Cache) lookup(key Key) *Entry {
+	if c.size <= 0 {
+		return nil
+	}
+	if entry := c.lookupMemory(key); entry != nil {
+		return entry
+	}
+	// NOTE: Read without the lock, so other lookups do not wait for the disk
+	entry := c.read(key)
+	if entry == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		// Put while reading, that entry is as new as the one on disk
+		return el.Value.(*item).entry
+	}
+	c.add(key, entry)
+	return entry
+}
+
+func (c *Cache) lookupMemory(key Key) *Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.order.MoveToFront(el)
+		return el.Value.(*item).entry
+	}
+	return nil
+}
+
+// c.mu must be held
+func (c *Cache) add(key Key, entry *Entry) {
+	if el, ok := c.items[key]; ok {
+		el.Value.(*item).entry = entry
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&item{key, entry})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		// NOTE: Dropped from memory only, it is still on disk
+		delete(c.items, oldest.Value.(*item).key)
+	}
+}
+
+func (c *Cache) remove(key Key) {
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		c.order.Remove(el)
+		delete(c.items, key)
+	}
+	c.mu.Unlock()
+	if c.dir != "" {
+		if err := os.Remove(c.path(key)); err != nil && !os.IsNotExist(err) {
+			log.Println(err)
+		}
+	}
+}
+
+func (c *Cache) path(key Key) string {
+	sum := sha256.Sum256([]byte(key.String()))
+	return filepath.Join(c.dir, fmt.Sprintf("%x.json", sum))
+}
+
+// Returns nil if the entry is not on disk. Unreadable entries count as
+// missing, they are written again when rendered.
+func (c *Cache) read(key Key) *Entry {
+	if c.dir == "" {
+		return nil
+	}
+	b, err := os.ReadFile(c.path(key))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Println(err)
+		}
+		return nil
+	}
+	var entry Entry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		log.Println(err)
+		return nil
+	}
+	return &entry
+}
+
+// NOTE: Failing to persist an entry is not fatal, it stays in memory
+func (c *Cache) write(key Key, entry *Entry) {
+	if c.dir == "" {
+		return
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	// NOTE: Written to a temporary file first so readers never see half an entry
+	tmp, err := os.CreateTemp(c.dir, "entry-*.tmp")
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	_, err = tmp.Write(b)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(key))
+	}
+	if err != nil {
+		log.Println(err)
+		os.Remove(tmp.Name())
+	}
+}
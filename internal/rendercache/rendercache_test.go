@@ -0,0 +1,90 @@
+package rendercache
+
+import (
+	"testing"
+)
+
+func valid(*Entry) bool   { return true }
+func invalid(*Entry) bool { return false }
+
+func TestCache(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		// Keys put in order, then looked up
+		put   []uint
+		found []uint
+		gone  []uint
+	}{
+		{"disabled", 0, []uint{1}, nil, []uint{1}},
+		{"found", 2, []uint{1, 2}, []uint{1, 2}, []uint{3}},
+		{"least recently used is dropped", 2, []uint{1, 2, 3}, []uint{2, 3}, []uint{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(&Config{Size: tt.size})
+			for _, revId := range tt.put {
+				c.Put(Key{RevId: revId}, &Entry{HTML: "html"})
+			}
+			for _, revId := range tt.found {
+				if _, ok := c.Get(Key{RevId: revId}, valid); !ok {
+					t.Errorf("revision %d not found", revId)
+				}
+			}
+			for _, revId := range tt.gone {
+				if _, ok := c.Get(Key{RevId: revId}, valid); ok {
+					t.Errorf("revision %d found", revId)
+				}
+			}
+			hits, misses := c.Stats()
+			if hits != uint64(len(tt.found)) || misses != uint64(len(tt.gone)) {
+				t.Errorf("got %d hits and %d misses", hits, misses)
+			}
+		})
+	}
+}
+
+func TestCacheKeys(t *testing.T) {
+	c := New(&Config{Size: 10})
+	c.Put(Key{RevId: 1, Version: "v1"}, &Entry{HTML: "v1"})
+	c.Put(Key{RevId: 1, Version: "v1", Variant: "edit:Home"}, &Entry{HTML: "edit"})
+	for _, key := range []Key{{RevId: 1, Version: "v2"}, {RevId: 1, Version: "v1", Variant: "edit:Away"}} {
+		if _, ok := c.Get(key, valid); ok {
+			t.Errorf("found %+v", key)
+		}
+	}
+	if e, ok := c.Get(Key{RevId: 1, Version: "v1", Variant: "edit:Home"}, valid); !ok || e.HTML != "edit" {
+		t.Errorf("got %+v, %v", e, ok)
+	}
+}
+
+func TestCacheInvalidEntriesAreRemoved(t *testing.T) {
+	dir := t.TempDir()
+	c := New(&Config{Size: 10, Dir: dir})
+	key := Key{RevId: 1}
+	c.Put(key, &Entry{HTML: "old"})
+	if _, ok := c.Get(key, invalid); ok {
+		t.Fatal("invalid entry returned")
+	}
+	if _, ok := c.Get(key, valid); ok {
+		t.Error("invalid entry kept in memory")
+	}
+	if _, ok := New(&Config{Size: 10, Dir: dir}).Get(key, valid); ok {
+		t.Error("invalid entry kept on disk")
+	}
+}
+
+func TestCacheDir(t *testing.T) {
+	dir := t.TempDir()
+	key := Key{RevId: 1, Version: "v1"}
+	c := New(&Config{Size: 1, Dir: dir})
+	c.Put(key, &Entry{HTML: "<p>hi</p>", Missing: []string{"Template:Box"}})
+	// NOTE: Drops the entry from memory, it is still on disk
+	c.Put(Key{RevId: 2}, &Entry{})
+	for name, c := range map[string]*Cache{"evicted": c, "restarted": New(&Config{Size: 1, Dir: dir})} {
+		e, ok := c.Get(key, valid)
+		if !ok || e.HTML != "<p>hi</p>" || len(e.Missing) != 1 || e.Missing[0] != "Template:Box" {
+			t.Errorf("%s: got %+v, %v", name, e, ok)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package repos
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/dev-mackan/gowiki/internal/repos/sqliterepo"
 	"github.com/dev-mackan/gowiki/pkg/models"
@@ -10,37 +11,65 @@ import (
 
 type Repository struct {
 	Bundled interface {
-		NewPageBundle(context.Context, string, string) error
-		UpdateBundledPage(context.Context, uint, string, string) error
+		NewPageBundle(context.Context, string, string, string) (uint, error)
+		UpdateBundledPage(context.Context, uint, []models.PageRename, string) error
+		RenamePages(context.Context, []models.PageRename) error
 		UpdateBundledPageContent(context.Context, uint, string) error
+		UpdateBundledPageContentFrom(context.Context, uint, uint, string) error
 		DeleteBundle(context.Context, uint) error
+		ReindexPage(context.Context, uint) error
+		Batch(context.Context, []models.BatchOp, bool) ([]models.BatchResult, error)
 	}
 	Page interface {
 		GetIDByTitle(context.Context, string) (uint, error)
 		GetByID(context.Context, uint) (*models.Page, error)
 		Create(context.Context, *models.Page) error
 		Update(context.Context, *models.Page) error
-		UpdateTitle(context.Context, uint, string) error
+		UpdateTitle(context.Context, uint, string, string) error
 		GetAll(context.Context) (*[]*models.Page, error)
+		GetAllInNamespace(context.Context, string) (*[]*models.Page, error)
+		GetSubpages(context.Context, []string, bool) (*[]*models.Page, error)
+		GetByIDs(context.Context, []uint) (*[]*models.Page, error)
+		GetByTitles(context.Context, []string) (*[]*models.Page, error)
+		Search(context.Context, []string, *string) (*[]*models.Page, error)
+		GetByProperties(context.Context, []models.PropertyFilter, *string) (*[]*models.Page, error)
+		GetTemplateCallNames(context.Context) ([]string, error)
+		GetByTemplateCalls(context.Context, []string) (*[]*models.Page, error)
 	}
 	Revision interface {
 		GetByID(context.Context, uint) (*models.Revision, error)
 		Create(context.Context, *models.Revision) error
 		Update(context.Context, *models.Revision) error
 		GetAllByPageID(context.Context, uint) (*[]*models.Revision, error)
+		GetByIDs(context.Context, []uint) (*[]*models.Revision, error)
+		GetAllByPageIDs(context.Context, []uint) (*[]*models.Revision, error)
 	}
 	Text interface {
 		GetByID(context.Context, uint) (*models.Text, error)
 		Create(context.Context, *models.Text) error
 		Update(context.Context, *models.Text) error
+		GetByIDs(context.Context, []uint) (*[]*models.Text, error)
+	}
+	Draft interface {
+		GetLatest(context.Context, string, uint) (*models.Draft, error)
+		GetByBaseRev(context.Context, string, uint, uint) (*models.Draft, error)
+		Save(context.Context, *models.Draft) error
+		DeleteByPageID(context.Context, string, uint) error
+	}
+	Idempotency interface {
+		Get(context.Context, string, time.Time) (*models.StoredResponse, error)
+		Save(context.Context, *models.StoredResponse) error
+		DeleteBefore(context.Context, time.Time) error
 	}
 }
 
 func NewSqlRepository(db *sql.DB) *Repository {
 	return &Repository{
-		Bundled:  sqliterepo.NewSqliteBundledRepository(db),
-		Page:     sqliterepo.NewSqlitePageRepository(db),
-		Revision: sqliterepo.NewSqliteRevisionRepository(db),
-		Text:     sqliterepo.NewSqliteTextRepository(db),
+		Bundled:     sqliterepo.NewSqliteBundledRepository(db),
+		Page:        sqliterepo.NewSqlitePageRepository(db),
+		Revision:    sqliterepo.NewSqliteRevisionRepository(db),
+		Text:        sqliterepo.NewSqliteTextRepository(db),
+		Draft:       sqliterepo.NewSqliteDraftRepository(db),
+		Idempotency: sqliterepo.NewSqliteIdempotencyRepository(db),
 	}
 }
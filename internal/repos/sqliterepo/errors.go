@@ -0,0 +1,51 @@
+package sqliterepo
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Translates database errors to wiki errors.
+// notFound is the code used when no rows were found.
+func parseErr(err error, notFound wikierr.Code) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return wikierr.Wrap(err, notFound, notFoundMessage(notFound))
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return wikierr.Wrap(err, wikierr.DuplicateTitle, "A page with that title already exists")
+	}
+	return err
+}
+
+func notFoundMessage(code wikierr.Code) string {
+	switch code {
+	case wikierr.PageNotFound:
+		return "Page not found"
+	case wikierr.RevisionNotFound:
+		return "Revision not found"
+	case wikierr.TextNotFound:
+		return "Text not found"
+	case wikierr.DraftNotFound:
+		return "Draft not found"
+	}
+	return "Resource not found"
+}
+
+// Returns a not found error if the statement did not affect any rows
+func expectRows(res sql.Result, notFound wikierr.Code) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return parseErr(sql.ErrNoRows, notFound)
+	}
+	return nil
+}
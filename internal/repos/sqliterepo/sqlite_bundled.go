@@ -3,6 +3,13 @@ package sqliterepo
 import (
 	"context"
 	"database/sql"
+	"fmt"
+
+	"github.com/dev-mackan/gowiki/internal/frontmatter"
+	"github.com/dev-mackan/gowiki/internal/transclusion"
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+	"github.com/dev-mackan/gowiki/pkg/models"
+	"github.com/dev-mackan/gowiki/pkg/utils"
 )
 
 type SqliteBundledRepository struct {
@@ -15,144 +22,336 @@ func NewSqliteBundledRepository(db *sql.DB) *SqliteBundledRepository {
 	}
 }
 
-func (s *SqliteBundledRepository) NewPageBundle(ctx context.Context, title string, content string) error {
-	textQuery := `INSERT INTO Text (content) VALUES (?) RETURNING text_id`
-	pageQuery := `INSERT INTO Page (title, latest_rev) VALUES (?,0) RETURNING page_id`
-	revQuery := `INSERT INTO Revision (text_id,page_id) VALUES (?,?) RETURNING rev_id`
-	pageUpdQuery := `UPDATE Page SET latest_rev=? WHERE page_id=?`
+func (s *SqliteBundledRepository) NewPageBundle(ctx context.Context, namespace string, title string, content string) (uint, error) {
+	var pageId uint
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		pageId, _, err = newPageBundleTx(ctx, tx, namespace, title, content)
+		return err
+	})
+	return pageId, err
+}
 
-	tx, err := s.db.Begin()
-	if err != nil {
+// Publishes the content and renames the page, and any subpages in renames
+func (s *SqliteBundledRepository) UpdateBundledPage(ctx context.Context, pageId uint, renames []models.PageRename, content string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := newRevisionTx(ctx, tx, pageId, content)
+		if err != nil {
+			return err
+		}
+		return renamePagesTx(ctx, tx, renames)
+	})
+}
+
+// Renames the pages together, e.g. a page and its subpages
+func (s *SqliteBundledRepository) RenamePages(ctx context.Context, renames []models.PageRename) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return renamePagesTx(ctx, tx, renames)
+	})
+}
+
+func (s *SqliteBundledRepository) UpdateBundledPageContent(ctx context.Context, pageId uint, content string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := newRevisionTx(ctx, tx, pageId, content)
 		return err
+	})
+}
+
+// Like UpdateBundledPageContent, but fails with an edit conflict unless
+// baseRev is still the latest revision of the page
+func (s *SqliteBundledRepository) UpdateBundledPageContentFrom(ctx context.Context, pageId uint, baseRev uint, content string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		// NOTE: Checked with an update, which takes the write lock, so no
+		// other revision can be published between the check and this one
+		query := `UPDATE Page SET latest_rev = latest_rev WHERE page_id = ? AND latest_rev = ?`
+		res, err := tx.ExecContext(ctx, query, pageId, baseRev)
+		if err != nil {
+			return parseErr(err, wikierr.PageNotFound)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			var latest uint
+			err = tx.QueryRowContext(ctx, `SELECT latest_rev FROM Page WHERE page_id = ?`, pageId).Scan(&latest)
+			if err != nil {
+				return parseErr(err, wikierr.PageNotFound)
+			}
+			return wikierr.New(wikierr.EditConflict, fmt.Sprintf("The page has changed since revision %d", baseRev))
+		}
+		_, err = newRevisionTx(ctx, tx, pageId, content)
+		return err
+	})
+}
+
+// Indexes the latest revision of the page again, e.g. for pages saved
+// before an index existed
+func (s *SqliteBundledRepository) ReindexPage(ctx context.Context, pageId uint) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		query := `SELECT t.content FROM Page p
+			JOIN Revision r ON r.rev_id = p.latest_rev
+			JOIN Text t ON t.text_id = r.text_id
+			WHERE p.page_id = ?`
+		var content sql.NullString
+		if err := tx.QueryRowContext(ctx, query, pageId).Scan(&content); err != nil {
+			return parseErr(err, wikierr.PageNotFound)
+		}
+		return indexContentTx(ctx, tx, pageId, content.String)
+	})
+}
+
+func (s *SqliteBundledRepository) DeleteBundle(ctx context.Context, pageId uint) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return deleteBundleTx(ctx, tx, pageId)
+	})
+}
+
+// Runs the operations in one transaction, in order. The transaction is only
+// committed if every operation succeeds and commit is set, e.g. not for dry runs.
+// On failure the results of the operations before the failing one are returned
+// with the error, so the failing operation is at index len(results).
+func (s *SqliteBundledRepository) Batch(ctx context.Context, ops []models.BatchOp, commit bool) ([]models.BatchResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, parseErr(err, wikierr.PageNotFound)
 	}
 	defer tx.Rollback()
 
-	var textId uint
-	err = tx.QueryRowContext(ctx, textQuery, content).Scan(&textId)
+	results := make([]models.BatchResult, 0, len(ops))
+	for _, op := range ops {
+		res, err := batchOpTx(ctx, tx, op)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, res)
+	}
+	if !commit {
+		return results, nil
+	}
+	err = tx.Commit()
 	if err != nil {
-		return err
+		return nil, parseErr(err, wikierr.PageNotFound)
 	}
+	return results, nil
+}
 
-	var pageId uint
-	err = tx.QueryRowContext(ctx, pageQuery, title).Scan(&pageId)
+func batchOpTx(ctx context.Context, tx *sql.Tx, op models.BatchOp) (models.BatchResult, error) {
+	res := models.BatchResult{PageId: op.PageId, Title: op.Title}
+	var err error
+	switch op.Op {
+	case models.BatchCreate:
+		res.PageId, res.RevId, err = newPageBundleTx(ctx, tx, op.Namespace, op.Title, op.Content)
+		return res, err
+	case models.BatchUpdateContent:
+		res.RevId, err = newRevisionTx(ctx, tx, op.PageId, op.Content)
+	case models.BatchRename:
+		err = renameTx(ctx, tx, op.PageId, op.Namespace, op.Title)
+	case models.BatchDelete:
+		// NOTE: The title is looked up first so the result says what was deleted
+		err = tx.QueryRowContext(ctx, `SELECT title FROM Page WHERE page_id = ?`, op.PageId).Scan(&res.Title)
+		if err != nil {
+			return res, parseErr(err, wikierr.PageNotFound)
+		}
+		return res, deleteBundleTx(ctx, tx, op.PageId)
+	default:
+		return res, wikierr.New(wikierr.ValidationFailed, fmt.Sprintf("Unknown operation %q", op.Op))
+	}
 	if err != nil {
-		return err
+		return res, err
 	}
-
-	var revId uint
-	err = tx.QueryRowContext(ctx, revQuery, textId, pageId).Scan(&revId)
+	// NOTE: The page is read back so the result has its current title and revision
+	query := `SELECT title, latest_rev FROM Page WHERE page_id = ?`
+	err = tx.QueryRowContext(ctx, query, op.PageId).Scan(&res.Title, &res.RevId)
 	if err != nil {
-		return err
+		return res, parseErr(err, wikierr.PageNotFound)
 	}
+	return res, nil
+}
 
-	_, err = tx.ExecContext(ctx, pageUpdQuery, revId, pageId)
+// Runs f in a transaction that is committed if f succeeds
+func (s *SqliteBundledRepository) inTx(ctx context.Context, f func(*sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
+		return parseErr(err, wikierr.PageNotFound)
+	}
+	defer tx.Rollback()
+	if err = f(tx); err != nil {
 		return err
 	}
 	err = tx.Commit()
 	if err != nil {
-		return err
+		return parseErr(err, wikierr.PageNotFound)
 	}
-
 	return nil
 }
 
-func (s *SqliteBundledRepository) UpdateBundledPage(ctx context.Context, pageId uint, title string, content string) error {
-	textQuery := `INSERT INTO Text (content) VALUES (?) RETURNING text_id`
-	revQuery := `INSERT INTO Revision (text_id,page_id) VALUES (?,?) RETURNING rev_id`
-	pageUpdQuery := `UPDATE Page SET latest_rev=?, title=? WHERE page_id=?`
+// Returns the ids of the new page and its first revision
+func newPageBundleTx(ctx context.Context, tx *sql.Tx, namespace string, title string, content string) (uint, uint, error) {
+	pageQuery := `INSERT INTO Page (title, title_key, namespace, latest_rev) VALUES (?,?,?,0) RETURNING page_id`
 
-	tx, err := s.db.Begin()
+	var pageId uint
+	err := tx.QueryRowContext(ctx, pageQuery, title, utils.TitleKey(title), namespace).Scan(&pageId)
 	if err != nil {
-		return err
+		return 0, 0, parseErr(err, wikierr.PageNotFound)
 	}
-	defer tx.Rollback()
+	revId, err := newRevisionTx(ctx, tx, pageId, content)
+	if err != nil {
+		return 0, 0, err
+	}
+	return pageId, revId, nil
+}
+
+// Adds a revision with the content and makes it the latest of the page.
+// Returns the id of the revision.
+func newRevisionTx(ctx context.Context, tx *sql.Tx, pageId uint, content string) (uint, error) {
+	textQuery := `INSERT INTO Text (content) VALUES (?) RETURNING text_id`
+	revQuery := `INSERT INTO Revision (text_id,page_id) VALUES (?,?) RETURNING rev_id`
+	pageUpdQuery := `UPDATE Page SET latest_rev=? WHERE page_id=?`
 
 	var textId uint
-	err = tx.QueryRowContext(ctx, textQuery, content).Scan(&textId)
+	err := tx.QueryRowContext(ctx, textQuery, content).Scan(&textId)
 	if err != nil {
-		return err
+		return 0, parseErr(err, wikierr.PageNotFound)
 	}
 
 	var revId uint
 	err = tx.QueryRowContext(ctx, revQuery, textId, pageId).Scan(&revId)
 	if err != nil {
-		return err
+		return 0, parseErr(err, wikierr.PageNotFound)
 	}
 
-	_, err = tx.ExecContext(ctx, pageUpdQuery, revId, title, pageId)
+	res, err := tx.ExecContext(ctx, pageUpdQuery, revId, pageId)
 	if err != nil {
-		return err
+		return 0, parseErr(err, wikierr.PageNotFound)
 	}
-	err = tx.Commit()
-	if err != nil {
-		return err
+	if err = expectRows(res, wikierr.PageNotFound); err != nil {
+		return 0, err
 	}
-	return nil
+	if err = indexContentTx(ctx, tx, pageId, content); err != nil {
+		return 0, err
+	}
+	return revId, nil
 }
 
-func (s *SqliteBundledRepository) UpdateBundledPageContent(ctx context.Context, pageId uint, content string) error {
-	textQuery := `INSERT INTO Text (content) VALUES (?) RETURNING text_id`
-	revQuery := `INSERT INTO Revision (text_id,page_id) VALUES (?,?) RETURNING rev_id`
-	pageUpdQuery := `UPDATE Page SET latest_rev=?  WHERE page_id=?`
-
-	tx, err := s.db.Begin()
-	if err != nil {
+// Indexes the properties and template calls of the new content of the page
+func indexContentTx(ctx context.Context, tx *sql.Tx, pageId uint, content string) error {
+	if err := indexPropertiesTx(ctx, tx, pageId, content); err != nil {
 		return err
 	}
-	defer tx.Rollback()
+	return indexTemplateCallsTx(ctx, tx, pageId, content)
+}
 
-	var textId uint
-	err = tx.QueryRowContext(ctx, textQuery, content).Scan(&textId)
+// Replaces the indexed properties of the page with the front matter of
+// its new content
+func indexPropertiesTx(ctx context.Context, tx *sql.Tx, pageId uint, content string) error {
+	props, err := frontmatter.Parse(content)
 	if err != nil {
-		return err
+		return wikierr.New(wikierr.ValidationFailed, err.Error())
 	}
-
-	var revId uint
-	err = tx.QueryRowContext(ctx, revQuery, textId, pageId).Scan(&revId)
+	_, err = tx.ExecContext(ctx, `DELETE FROM PageProperty WHERE page_id = ?`, pageId)
 	if err != nil {
-		return err
+		return parseErr(err, wikierr.PageNotFound)
+	}
+	for _, prop := range frontmatter.Flatten(props) {
+		query := `INSERT INTO PageProperty (page_id, name, value) VALUES (?,?,?)`
+		_, err = tx.ExecContext(ctx, query, pageId, prop.Name, prop.Value)
+		if err != nil {
+			return parseErr(err, wikierr.PageNotFound)
+		}
 	}
+	return nil
+}
 
-	_, err = tx.ExecContext(ctx, pageUpdQuery, revId, pageId)
+// Replaces the indexed template calls of the page with those of its new
+// content. The names are kept as written, as the template a name refers
+// to depends on the configured namespaces.
+func indexTemplateCallsTx(ctx context.Context, tx *sql.Tx, pageId uint, content string) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM TemplateCall WHERE page_id = ?`, pageId)
 	if err != nil {
-		return err
+		return parseErr(err, wikierr.PageNotFound)
 	}
-	err = tx.Commit()
+	seen := make(map[string]bool)
+	for _, name := range transclusion.Calls(content) {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		_, err = tx.ExecContext(ctx, `INSERT INTO TemplateCall (page_id, name) VALUES (?,?)`, pageId, name)
+		if err != nil {
+			return parseErr(err, wikierr.PageNotFound)
+		}
+	}
+	return nil
+}
+
+func renameTx(ctx context.Context, tx *sql.Tx, pageId uint, namespace string, title string) error {
+	query := `UPDATE Page SET title = ?, title_key = ?, namespace = ? WHERE page_id = ?`
+	res, err := tx.ExecContext(ctx, query, title, utils.TitleKey(title), namespace, pageId)
 	if err != nil {
-		return err
+		return parseErr(err, wikierr.PageNotFound)
+	}
+	return expectRows(res, wikierr.PageNotFound)
+}
+
+func renamePagesTx(ctx context.Context, tx *sql.Tx, renames []models.PageRename) error {
+	if len(renames) > 1 {
+		// NOTE: A page may be renamed to the old title of another one, e.g. when
+		// moving A to A/A, so the keys are cleared first. "#" is never in a title.
+		pageIds := make([]uint, len(renames))
+		for i, rn := range renames {
+			pageIds[i] = rn.PageId
+		}
+		query := `UPDATE Page SET title_key = '#' || page_id WHERE page_id IN (` + placeholders(len(pageIds)) + `)`
+		_, err := tx.ExecContext(ctx, query, uintArgs(pageIds)...)
+		if err != nil {
+			return parseErr(err, wikierr.PageNotFound)
+		}
+	}
+	for _, rn := range renames {
+		if err := renameTx(ctx, tx, rn.PageId, rn.Namespace, rn.Title); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (s *SqliteBundledRepository) DeleteBundle(ctx context.Context, pageId uint) error {
+func deleteBundleTx(ctx context.Context, tx *sql.Tx, pageId uint) error {
 	pageQuery := `DELETE FROM Page WHERE page_id = ?`
 	revQuery := `DELETE FROM Revision WHERE page_id = ?`
 	textQuery := `DELETE FROM Text WHERE text_id IN (SELECT text_id FROM Revision WHERE page_id=?)`
-	tx, err := s.db.Begin()
+	draftQuery := `DELETE FROM Draft WHERE page_id = ?`
+	propQuery := `DELETE FROM PageProperty WHERE page_id = ?`
+	callQuery := `DELETE FROM TemplateCall WHERE page_id = ?`
+
+	_, err := tx.ExecContext(ctx, draftQuery, pageId)
 	if err != nil {
-		return err
+		return parseErr(err, wikierr.PageNotFound)
 	}
-	defer tx.Rollback()
 
-	_, err = tx.ExecContext(ctx, textQuery, pageId)
+	_, err = tx.ExecContext(ctx, propQuery, pageId)
 	if err != nil {
-		return err
+		return parseErr(err, wikierr.PageNotFound)
 	}
 
-	_, err = tx.ExecContext(ctx, revQuery, pageId)
+	_, err = tx.ExecContext(ctx, callQuery, pageId)
 	if err != nil {
-		return err
+		return parseErr(err, wikierr.PageNotFound)
 	}
 
-	_, err = tx.ExecContext(ctx, pageQuery, pageId)
+	_, err = tx.ExecContext(ctx, textQuery, pageId)
 	if err != nil {
-		return err
+		return parseErr(err, wikierr.PageNotFound)
 	}
 
-	err = tx.Commit()
+	_, err = tx.ExecContext(ctx, revQuery, pageId)
 	if err != nil {
-		return err
+		return parseErr(err, wikierr.PageNotFound)
 	}
-	return nil
+
+	res, err := tx.ExecContext(ctx, pageQuery, pageId)
+	if err != nil {
+		return parseErr(err, wikierr.PageNotFound)
+	}
+	return expectRows(res, wikierr.PageNotFound)
 }
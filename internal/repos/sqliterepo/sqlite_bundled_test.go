@@ -0,0 +1,83 @@
+package sqliterepo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dev-mackan/gowiki/internal/testutil"
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+)
+
+func TestUpdateBundledPageContentFrom(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		pageId  uint
+		baseRev uint
+		code    wikierr.Code
+		latest  uint
+	}{
+		{"latest revision", 1, 2, "", 3},
+		{"older revision", 1, 1, wikierr.EditConflict, 2},
+		{"missing page", 9, 2, wikierr.PageNotFound, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewSqliteBundledRepository(testutil.NewDB(t))
+			pageId, err := repo.NewPageBundle(ctx, "", "Home", "first")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = repo.UpdateBundledPageContent(ctx, pageId, "second"); err != nil {
+				t.Fatal(err)
+			}
+			err = repo.UpdateBundledPageContentFrom(ctx, tt.pageId, tt.baseRev, "third")
+			if tt.code == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.code != "" && wikierr.CodeOf(err) != tt.code {
+				t.Fatalf("got error %v, want %s", err, tt.code)
+			}
+			var latest uint
+			if err = repo.db.QueryRow(`SELECT latest_rev FROM Page WHERE page_id = ?`, pageId).Scan(&latest); err != nil {
+				t.Fatal(err)
+			}
+			if latest != tt.latest {
+				t.Errorf("got latest revision %d, want %d", latest, tt.latest)
+			}
+		})
+	}
+}
+
+func TestReindexPage(t *testing.T) {
+	ctx := context.Background()
+	repo := NewSqliteBundledRepository(testutil.NewDB(t))
+	pageId, err := repo.NewPageBundle(ctx, "", "Home", "---\nowner: alice\ntags: [a, b]\n---\n{{Box}} {{Box}} {{Card}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// NOTE: As if the page was saved before it was indexed
+	if _, err = repo.db.Exec(`DELETE FROM PageProperty; DELETE FROM TemplateCall`); err != nil {
+		t.Fatal(err)
+	}
+	if err = repo.ReindexPage(ctx, pageId); err != nil {
+		t.Fatal(err)
+	}
+	var props int
+	if err = repo.db.QueryRow(`SELECT COUNT(*) FROM PageProperty WHERE page_id = ?`, pageId).Scan(&props); err != nil {
+		t.Fatal(err)
+	}
+	if props != 3 {
+		t.Errorf("got %d properties, want 3", props)
+	}
+	var calls int
+	if err = repo.db.QueryRow(`SELECT COUNT(*) FROM TemplateCall WHERE page_id = ?`, pageId).Scan(&calls); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("got %d template calls, want 2", calls)
+	}
+	if err = repo.ReindexPage(ctx, 9); wikierr.CodeOf(err) != wikierr.PageNotFound {
+		t.Errorf("got error %v, want %s", err, wikierr.PageNotFound)
+	}
+}
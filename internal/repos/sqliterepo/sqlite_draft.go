@@ -0,0 +1,55 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+	"github.com/dev-mackan/gowiki/pkg/models"
+)
+
+type SqliteDraftRepository struct {
+	db *sql.DB
+}
+
+func NewSqliteDraftRepository(db *sql.DB) *SqliteDraftRepository {
+	return &SqliteDraftRepository{
+		db,
+	}
+}
+
+// Returns the most recently saved draft of a page, regardless of base revision
+func (r *SqliteDraftRepository) GetLatest(ctx context.Context, owner string, pageId uint) (*models.Draft, error) {
+	query := `SELECT owner, page_id, base_rev, content, updated_at FROM Draft
+		WHERE owner = ? AND page_id = ? ORDER BY updated_at DESC LIMIT 1`
+	var draft models.Draft
+	err := r.db.QueryRowContext(ctx, query, owner, pageId).Scan(&draft.Owner, &draft.PageId, &draft.BaseRev, &draft.Content, &draft.UpdatedAt)
+	if err != nil {
+		return nil, parseErr(err, wikierr.DraftNotFound)
+	}
+	return &draft, nil
+}
+
+func (r *SqliteDraftRepository) GetByBaseRev(ctx context.Context, owner string, pageId uint, baseRev uint) (*models.Draft, error) {
+	query := `SELECT owner, page_id, base_rev, content, updated_at FROM Draft
+		WHERE owner = ? AND page_id = ? AND base_rev = ?`
+	var draft models.Draft
+	err := r.db.QueryRowContext(ctx, query, owner, pageId, baseRev).Scan(&draft.Owner, &draft.PageId, &draft.BaseRev, &draft.Content, &draft.UpdatedAt)
+	if err != nil {
+		return nil, parseErr(err, wikierr.DraftNotFound)
+	}
+	return &draft, nil
+}
+
+func (r *SqliteDraftRepository) Save(ctx context.Context, draft *models.Draft) error {
+	query := `INSERT INTO Draft (owner, page_id, base_rev, content) VALUES (?,?,?,?)
+		ON CONFLICT (owner, page_id, base_rev) DO UPDATE SET content = excluded.content, updated_at = CURRENT_TIMESTAMP`
+	_, err := r.db.ExecContext(ctx, query, draft.Owner, draft.PageId, draft.BaseRev, draft.Content)
+	return parseErr(err, wikierr.DraftNotFound)
+}
+
+func (r *SqliteDraftRepository) DeleteByPageID(ctx context.Context, owner string, pageId uint) error {
+	query := `DELETE FROM Draft WHERE owner = ? AND page_id = ?`
+	_, err := r.db.ExecContext(ctx, query, owner, pageId)
+	return parseErr(err, wikierr.DraftNotFound)
+}
@@ -0,0 +1,67 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/dev-mackan/gowiki/pkg/models"
+)
+
+type SqliteIdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewSqliteIdempotencyRepository(db *sql.DB) *SqliteIdempotencyRepository {
+	return &SqliteIdempotencyRepository{
+		db,
+	}
+}
+
+// Returns the response stored for the key since the given time,
+// or nil if there is none
+func (r *SqliteIdempotencyRepository) Get(ctx context.Context, key string, since time.Time) (*models.StoredResponse, error) {
+	query := `SELECT idem_key, fingerprint, status, header, body, created_at FROM IdempotencyKey
+		WHERE idem_key = ? AND created_at >= ?`
+	var res models.StoredResponse
+	var header string
+	var createdAt int64
+	err := r.db.QueryRowContext(ctx, query, key, since.Unix()).Scan(&res.Key, &res.Fingerprint, &res.Status, &header, &res.Body, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal([]byte(header), &res.Header); err != nil {
+		return nil, err
+	}
+	res.CreatedAt = time.Unix(createdAt, 0).UTC()
+	return &res, nil
+}
+
+// Replaces an expired response stored for the same key
+func (r *SqliteIdempotencyRepository) Save(ctx context.Context, res *models.StoredResponse) error {
+	query := `INSERT INTO IdempotencyKey (idem_key, fingerprint, status, header, body, created_at) VALUES (?,?,?,?,?,?)
+		ON CONFLICT (idem_key) DO UPDATE SET fingerprint = excluded.fingerprint, status = excluded.status,
+		header = excluded.header, body = excluded.body, created_at = excluded.created_at`
+	header, err := json.Marshal(res.Header)
+	if err != nil {
+		return err
+	}
+	body := res.Body
+	if body == nil {
+		// NOTE: A nil slice is stored as NULL
+		body = []byte{}
+	}
+	_, err = r.db.ExecContext(ctx, query, res.Key, res.Fingerprint, res.Status, string(header), body, res.CreatedAt.Unix())
+	return err
+}
+
+func (r *SqliteIdempotencyRepository) DeleteBefore(ctx context.Context, before time.Time) error {
+	query := `DELETE FROM IdempotencyKey WHERE created_at < ?`
+	_, err := r.db.ExecContext(ctx, query, before.Unix())
+	return err
+}
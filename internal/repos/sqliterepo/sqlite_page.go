@@ -3,8 +3,11 @@ package sqliterepo
 import (
 	"context"
 	"database/sql"
+	"strings"
 
+	"github.com/dev-mackan/gowiki/internal/wikierr"
 	"github.com/dev-mackan/gowiki/pkg/models"
+	"github.com/dev-mackan/gowiki/pkg/utils"
 )
 
 type SqlitePageRepository struct {
@@ -18,11 +21,11 @@ func NewSqlitePageRepository(db *sql.DB) *SqlitePageRepository {
 }
 
 func (r *SqlitePageRepository) GetIDByTitle(ctx context.Context, title string) (uint, error) {
-	query := `SELECT page_id FROM Page WHERE UPPER(title)=UPPER(?)`
+	query := `SELECT page_id FROM Page WHERE title_key = ?`
 	var id uint
-	err := r.db.QueryRowContext(ctx, query, title).Scan(&id)
+	err := r.db.QueryRowContext(ctx, query, utils.TitleKey(title)).Scan(&id)
 	if err != nil {
-		return 0, err
+		return 0, parseErr(err, wikierr.PageNotFound)
 	}
 	return id, nil
 }
@@ -35,48 +38,198 @@ func (r *SqlitePageRepository) Update(ctx context.Context, page *models.Page) er
 	//query := `UPDATE Page SET latest_rev = ?, title = ? WHERE page_id = ?`
 	return nil
 }
-func (r *SqlitePageRepository) UpdateTitle(ctx context.Context, pageId uint, title string) error {
-	query := `UPDATE Page SET title = ? WHERE page_id = ?`
+func (r *SqlitePageRepository) UpdateTitle(ctx context.Context, pageId uint, namespace string, title string) error {
+	query := `UPDATE Page SET title = ?, title_key = ?, namespace = ? WHERE page_id = ?`
 	tx, err := r.db.Begin()
 	if err != nil {
-		return err
+		return parseErr(err, wikierr.PageNotFound)
 	}
 	defer tx.Rollback()
-	_, err = tx.ExecContext(ctx, query, title, pageId)
+	res, err := tx.ExecContext(ctx, query, title, utils.TitleKey(title), namespace, pageId)
 	if err != nil {
+		return parseErr(err, wikierr.PageNotFound)
+	}
+	if err = expectRows(res, wikierr.PageNotFound); err != nil {
 		return err
 	}
 	err = tx.Commit()
 	if err != nil {
-		return err
+		return parseErr(err, wikierr.PageNotFound)
 	}
 	return nil
 }
 
 func (r *SqlitePageRepository) GetAll(ctx context.Context) (*[]*models.Page, error) {
-	query := `SELECT page_id, title, latest_rev, created_at FROM Page`
-	rows, err := r.db.QueryContext(ctx, query)
+	query := `SELECT ` + pageColumns + ` FROM Page`
+	return r.queryPages(ctx, query)
+}
+
+func (r *SqlitePageRepository) GetAllInNamespace(ctx context.Context, namespace string) (*[]*models.Page, error) {
+	query := `SELECT ` + pageColumns + ` FROM Page WHERE namespace = ? ORDER BY title`
+	return r.queryPages(ctx, query, namespace)
+}
+
+// Returns the pages below any of the titles, only their children unless
+// all is set
+func (r *SqlitePageRepository) GetSubpages(ctx context.Context, titles []string, all bool) (*[]*models.Page, error) {
+	if len(titles) == 0 {
+		return &[]*models.Page{}, nil
+	}
+	var conds []string
+	var args []any
+	for _, title := range titles {
+		prefix := escapeLike(utils.TitleKey(title) + "/")
+		if all {
+			conds = append(conds, `title_key LIKE ? ESCAPE '\'`)
+			args = append(args, prefix+"%")
+		} else {
+			conds = append(conds, `(title_key LIKE ? ESCAPE '\' AND title_key NOT LIKE ? ESCAPE '\')`)
+			args = append(args, prefix+"%", prefix+"%/%")
+		}
+	}
+	query := `SELECT ` + pageColumns + ` FROM Page WHERE ` + strings.Join(conds, " OR ") + ` ORDER BY title`
+	return r.queryPages(ctx, query, args...)
+}
+
+func (r *SqlitePageRepository) GetByTitles(ctx context.Context, titles []string) (*[]*models.Page, error) {
+	if len(titles) == 0 {
+		return &[]*models.Page{}, nil
+	}
+	keys := make([]any, len(titles))
+	for i, title := range titles {
+		keys[i] = utils.TitleKey(title)
+	}
+	query := `SELECT ` + pageColumns + ` FROM Page WHERE title_key IN (` + placeholders(len(keys)) + `)`
+	return r.queryPages(ctx, query, keys...)
+}
+
+func (r *SqlitePageRepository) GetByID(ctx context.Context, pageId uint) (*models.Page, error) {
+	query := `SELECT ` + pageColumns + ` FROM Page WHERE page_id=?`
+	page, err := scanPage(r.db.QueryRowContext(ctx, query, pageId))
 	if err != nil {
-		return nil, err
+		return nil, parseErr(err, wikierr.PageNotFound)
 	}
-	pages := make([]*models.Page, 0)
+	return page, nil
+}
+
+func (r *SqlitePageRepository) GetByIDs(ctx context.Context, pageIds []uint) (*[]*models.Page, error) {
+	if len(pageIds) == 0 {
+		return &[]*models.Page{}, nil
+	}
+	query := `SELECT ` + pageColumns + ` FROM Page WHERE page_id IN (` + placeholders(len(pageIds)) + `)`
+	return r.queryPages(ctx, query, uintArgs(pageIds)...)
+}
+
+// Returns the pages whose title or latest content contains every term,
+// ignoring case. Titles are matched by their case folded key, as LIKE
+// only ignores the case of ASCII letters. A nil namespace searches
+// every namespace.
+func (r *SqlitePageRepository) Search(ctx context.Context, terms []string, namespace *string) (*[]*models.Page, error) {
+	if len(terms) == 0 {
+		return &[]*models.Page{}, nil
+	}
+	var conds []string
+	var args []any
+	for _, term := range terms {
+		pattern := "%" + escapeLike(term) + "%"
+		keyPattern := "%" + escapeLike(utils.TitleKey(term)) + "%"
+		conds = append(conds, `(p.title_key LIKE ? ESCAPE '\' OR t.content LIKE ? ESCAPE '\')`)
+		args = append(args, keyPattern, pattern)
+	}
+	if namespace != nil {
+		conds = append(conds, `p.namespace = ?`)
+		args = append(args, *namespace)
+	}
+	query := `SELECT p.page_id, p.title, p.namespace, p.latest_rev, p.created_at FROM Page p
+		JOIN Revision r ON r.rev_id = p.latest_rev
+		JOIN Text t ON t.text_id = r.text_id
+		WHERE ` + strings.Join(conds, " AND ") + ` ORDER BY p.title`
+	return r.queryPages(ctx, query, args...)
+}
+
+// Returns the pages whose latest revision has every property, see
+// models.PropertyFilter. A nil namespace matches every namespace.
+func (r *SqlitePageRepository) GetByProperties(ctx context.Context, filters []models.PropertyFilter, namespace *string) (*[]*models.Page, error) {
+	if len(filters) == 0 {
+		return &[]*models.Page{}, nil
+	}
+	var conds []string
+	var args []any
+	for _, f := range filters {
+		cond := `EXISTS (SELECT 1 FROM PageProperty pp WHERE pp.page_id = Page.page_id AND pp.name = ?`
+		args = append(args, f.Name)
+		if f.Value != nil {
+			cond += ` AND pp.value = ?`
+			args = append(args, *f.Value)
+		}
+		conds = append(conds, cond+`)`)
+	}
+	if namespace != nil {
+		conds = append(conds, `namespace = ?`)
+		args = append(args, *namespace)
+	}
+	query := `SELECT ` + pageColumns + ` FROM Page WHERE ` + strings.Join(conds, " AND ") + ` ORDER BY title`
+	return r.queryPages(ctx, query, args...)
+}
+
+// Returns every name templates are called by, once
+func (r *SqlitePageRepository) GetTemplateCallNames(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT name FROM TemplateCall`)
+	if err != nil {
+		return nil, parseErr(err, wikierr.PageNotFound)
+	}
+	defer rows.Close()
+	var names []string
 	for rows.Next() {
-		var page models.Page
-		err = rows.Scan(&page.PageId, &page.Title, &page.LatestRev, &page.CreatedAt)
-		if err != nil {
-			continue
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, parseErr(err, wikierr.PageNotFound)
 		}
-		pages = append(pages, &page)
+		names = append(names, name)
 	}
-	return &pages, nil
+	return names, rows.Err()
 }
 
-func (r *SqlitePageRepository) GetByID(ctx context.Context, pageId uint) (*models.Page, error) {
-	query := `SELECT page_id, title, latest_rev, created_at FROM Page WHERE page_id=?`
+// Returns the pages whose latest revision calls a template by any of the
+// names
+func (r *SqlitePageRepository) GetByTemplateCalls(ctx context.Context, names []string) (*[]*models.Page, error) {
+	if len(names) == 0 {
+		return &[]*models.Page{}, nil
+	}
+	args := make([]any, len(names))
+	for i, name := range names {
+		args[i] = name
+	}
+	query := `SELECT ` + pageColumns + ` FROM Page WHERE page_id IN
+		(SELECT page_id FROM TemplateCall WHERE name IN (` + placeholders(len(names)) + `)) ORDER BY title`
+	return r.queryPages(ctx, query, args...)
+}
+
+const pageColumns = `page_id, title, namespace, latest_rev, created_at`
+
+// Scans a row of pageColumns
+func scanPage(row interface{ Scan(...any) error }) (*models.Page, error) {
 	var page models.Page
-	err := r.db.QueryRowContext(ctx, query, pageId).Scan(&page.PageId, &page.Title, &page.LatestRev, &page.CreatedAt)
+	err := row.Scan(&page.PageId, &page.Title, &page.Namespace, &page.LatestRev, &page.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
 	return &page, nil
 }
+
+func (r *SqlitePageRepository) queryPages(ctx context.Context, query string, args ...any) (*[]*models.Page, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, parseErr(err, wikierr.PageNotFound)
+	}
+	defer rows.Close()
+	pages := make([]*models.Page, 0)
+	for rows.Next() {
+		page, err := scanPage(rows)
+		if err != nil {
+			return nil, parseErr(err, wikierr.PageNotFound)
+		}
+		pages = append(pages, page)
+	}
+	return &pages, rows.Err()
+}
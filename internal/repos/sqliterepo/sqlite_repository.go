@@ -1,7 +1,28 @@
 package sqliterepo
 
-import "database/sql"
+import (
+	"database/sql"
+	"strings"
+)
 
 type SqliteRepository struct {
 	db *sql.DB
 }
+
+// Returns "?,?,?" for n values, used for IN clauses
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+func uintArgs(ids []uint) []any {
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
+
+// Escapes the wildcards of a LIKE pattern, the query must use ESCAPE '\'
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
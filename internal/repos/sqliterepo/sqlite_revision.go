@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/dev-mackan/gowiki/internal/wikierr"
 	"github.com/dev-mackan/gowiki/pkg/models"
 )
 
@@ -29,16 +30,17 @@ func (r *SqliteRevisionRepository) GetByID(ctx context.Context, revId uint) (*mo
 	var rev models.Revision
 	err := r.db.QueryRowContext(ctx, query, revId).Scan(&rev.RevId, &rev.PageId, &rev.TextId, &rev.CreatedAt)
 	if err != nil {
-		return nil, err
+		return nil, parseErr(err, wikierr.RevisionNotFound)
 	}
 	return &rev, nil
 }
 func (r *SqliteRevisionRepository) GetAllByPageID(ctx context.Context, pageId uint) (*[]*models.Revision, error) {
-	query := `SELECT rev_id, page_id, text_id, created_at FROM Revision WHERE page_id = ?`
+	query := `SELECT rev_id, page_id, text_id, created_at FROM Revision WHERE page_id = ? ORDER BY rev_id`
 	rows, err := r.db.QueryContext(ctx, query, pageId)
 	if err != nil {
-		return nil, err
+		return nil, parseErr(err, wikierr.RevisionNotFound)
 	}
+	defer rows.Close()
 	revs := make([]*models.Revision, 0)
 	for rows.Next() {
 		var rev models.Revision
@@ -50,3 +52,35 @@ func (r *SqliteRevisionRepository) GetAllByPageID(ctx context.Context, pageId ui
 	}
 	return &revs, nil
 }
+
+func (r *SqliteRevisionRepository) GetByIDs(ctx context.Context, revIds []uint) (*[]*models.Revision, error) {
+	query := `SELECT rev_id, page_id, text_id, created_at FROM Revision WHERE rev_id IN (` + placeholders(len(revIds)) + `)`
+	return r.getAllIn(ctx, query, revIds)
+}
+
+// Returns the revisions of all the pages, ordered by page and revision
+func (r *SqliteRevisionRepository) GetAllByPageIDs(ctx context.Context, pageIds []uint) (*[]*models.Revision, error) {
+	query := `SELECT rev_id, page_id, text_id, created_at FROM Revision WHERE page_id IN (` + placeholders(len(pageIds)) + `) ORDER BY page_id, rev_id`
+	return r.getAllIn(ctx, query, pageIds)
+}
+
+func (r *SqliteRevisionRepository) getAllIn(ctx context.Context, query string, ids []uint) (*[]*models.Revision, error) {
+	revs := make([]*models.Revision, 0, len(ids))
+	if len(ids) == 0 {
+		return &revs, nil
+	}
+	rows, err := r.db.QueryContext(ctx, query, uintArgs(ids)...)
+	if err != nil {
+		return nil, parseErr(err, wikierr.RevisionNotFound)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var rev models.Revision
+		err = rows.Scan(&rev.RevId, &rev.PageId, &rev.TextId, &rev.CreatedAt)
+		if err != nil {
+			return nil, parseErr(err, wikierr.RevisionNotFound)
+		}
+		revs = append(revs, &rev)
+	}
+	return &revs, rows.Err()
+}
@@ -3,6 +3,7 @@ package sqliterepo
 import (
 	"context"
 	"database/sql"
+	"github.com/dev-mackan/gowiki/internal/wikierr"
 	"github.com/dev-mackan/gowiki/pkg/models"
 )
 
@@ -28,7 +29,29 @@ func (r *SqliteTextRepository) GetByID(ctx context.Context, textId uint) (*model
 	var text models.Text
 	err := r.db.QueryRowContext(ctx, query, textId).Scan(&text.TextId, &text.Content, &text.CreatedAt)
 	if err != nil {
-		return nil, err
+		return nil, parseErr(err, wikierr.TextNotFound)
 	}
 	return &text, nil
 }
+
+func (r *SqliteTextRepository) GetByIDs(ctx context.Context, textIds []uint) (*[]*models.Text, error) {
+	texts := make([]*models.Text, 0, len(textIds))
+	if len(textIds) == 0 {
+		return &texts, nil
+	}
+	query := `SELECT text_id, content, created_at FROM Text WHERE text_id IN (` + placeholders(len(textIds)) + `)`
+	rows, err := r.db.QueryContext(ctx, query, uintArgs(textIds)...)
+	if err != nil {
+		return nil, parseErr(err, wikierr.TextNotFound)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var text models.Text
+		err = rows.Scan(&text.TextId, &text.Content, &text.CreatedAt)
+		if err != nil {
+			return nil, parseErr(err, wikierr.TextNotFound)
+		}
+		texts = append(texts, &text)
+	}
+	return &texts, rows.Err()
+}
@@ -0,0 +1,123 @@
+package reposervice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+	"github.com/dev-mackan/gowiki/pkg/models"
+)
+
+// Upper bound on the operations of one batch, so a batch cannot hold the
+// database for too long
+const MaxBatchOps = 1000
+
+// Returned by RunBatch when an operation fails. Nothing of the batch is saved.
+type BatchError struct {
+	// Index of the failing operation
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("operation %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// Runs the operations in one transaction, either all of them are saved or
+// none. With dryRun the operations are run but never saved. Titles and
+// namespace permissions are checked before anything is written.
+func (rs *RepoService) RunBatch(ctx context.Context, ops []models.BatchOp, dryRun bool) ([]models.BatchResult, error) {
+	if len(ops) == 0 {
+		return nil, wikierr.New(wikierr.ValidationFailed, "The batch has no operations")
+	}
+	if len(ops) > MaxBatchOps {
+		return nil, wikierr.New(wikierr.ValidationFailed, fmt.Sprintf("A batch can have at most %d operations", MaxBatchOps))
+	}
+	ops = append([]models.BatchOp(nil), ops...)
+	pages, err := rs.batchPages(ctx, ops)
+	if err != nil {
+		return nil, err
+	}
+	for i := range ops {
+		if err := rs.prepareBatchOp(ctx, &ops[i], pages); err != nil {
+			return nil, &BatchError{Index: i, Err: err}
+		}
+	}
+
+	results, err := rs.repo.Bundled.Batch(ctx, ops, !dryRun)
+	if err != nil {
+		return nil, &BatchError{Index: len(results), Err: handleErr(err)}
+	}
+	if !dryRun {
+		at := time.Now().UTC()
+		for i, res := range results {
+			rs.changes.publish(models.PageChange{
+				Kind:   batchChangeKinds[ops[i].Op],
+				PageId: res.PageId,
+				Title:  res.Title,
+				RevId:  res.RevId,
+				At:     at,
+			})
+		}
+	}
+	return results, nil
+}
+
+var batchChangeKinds = map[models.BatchOpKind]models.PageChangeKind{
+	models.BatchCreate:        models.PageCreated,
+	models.BatchUpdateContent: models.PageEdited,
+	models.BatchRename:        models.PageRenamed,
+	models.BatchDelete:        models.PageDeleted,
+}
+
+// Returns the existing pages the operations refer to, by id
+func (rs *RepoService) batchPages(ctx context.Context, ops []models.BatchOp) (map[uint]*models.Page, error) {
+	var pageIds []uint
+	for _, op := range ops {
+		if op.PageId != 0 {
+			pageIds = append(pageIds, op.PageId)
+		}
+	}
+	pages, err := rs.repo.Page.GetByIDs(ctx, pageIds)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	byId := make(map[uint]*models.Page, len(*pages))
+	for _, page := range *pages {
+		byId[page.PageId] = page
+	}
+	return byId, nil
+}
+
+func (rs *RepoService) prepareBatchOp(ctx context.Context, op *models.BatchOp, pages map[uint]*models.Page) error {
+	switch op.Op {
+	case models.BatchCreate, models.BatchRename:
+		ns, title, err := rs.parseTitle(op.Title)
+		if err != nil {
+			return err
+		}
+		if err = rs.checkEdit(ctx, ns); err != nil {
+			return err
+		}
+		op.Title, op.Namespace = title, ns.Name
+	case models.BatchUpdateContent, models.BatchDelete:
+	default:
+		return wikierr.New(wikierr.ValidationFailed, fmt.Sprintf("Unknown operation %q", op.Op))
+	}
+	if op.Op == models.BatchCreate {
+		return nil
+	}
+	if op.PageId == 0 {
+		return wikierr.New(wikierr.ValidationFailed, "The operation needs a page_id")
+	}
+	// NOTE: Unknown pages are left for the batch to fail on
+	if page, ok := pages[op.PageId]; ok {
+		return rs.checkEditPages(ctx, page)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package reposervice
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/dev-mackan/gowiki/pkg/models"
+)
+
+// Subscribers that fall this far behind are dropped
+const changeBuffer = 64
+
+// Ends a subscription whose subscriber fell behind, changes were missed
+var ErrChangesMissed = errors.New("fell behind the changes, some were missed")
+
+// Changes made through the repo service, see SubscribeChanges
+type ChangeSubscription struct {
+	ch chan models.PageChange
+	// Set before ch is closed
+	err error
+}
+
+// Closed when the subscription ends, after which Err tells why
+func (s *ChangeSubscription) Changes() <-chan models.PageChange {
+	return s.ch
+}
+
+// Returns ErrChangesMissed if the subscriber was dropped for falling
+// behind, or nil if its context ended. Only valid once Changes is closed.
+func (s *ChangeSubscription) Err() error {
+	return s.err
+}
+
+// Fans page changes out to subscribers in this process
+type changeFeed struct {
+	mu   sync.Mutex
+	subs map[*ChangeSubscription]bool
+}
+
+func newChangeFeed() *changeFeed {
+	return &changeFeed{subs: make(map[*ChangeSubscription]bool)}
+}
+
+func (f *changeFeed) publish(change models.PageChange) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for sub := range f.subs {
+		select {
+		case sub.ch <- change:
+		default:
+			f.end(sub, ErrChangesMissed)
+		}
+	}
+}
+
+func (f *changeFeed) subscribe(ctx context.Context) *ChangeSubscription {
+	sub := &ChangeSubscription{ch: make(chan models.PageChange, changeBuffer)}
+	f.mu.Lock()
+	f.subs[sub] = true
+	f.mu.Unlock()
+	go func() {
+		<-ctx.Done()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.end(sub, nil)
+	}()
+	return sub
+}
+
+// f.mu must be held
+func (f *changeFeed) end(sub *ChangeSubscription, err error) {
+	if !f.subs[sub] {
+		return
+	}
+	delete(f.subs, sub)
+	sub.err = err
+	close(sub.ch)
+}
+
+// Returns the changes made through this service until ctx is done, or
+// until the subscriber falls behind, see ChangeSubscription.Err
+func (rs *RepoService) SubscribeChanges(ctx context.Context) *ChangeSubscription {
+	return rs.changes.subscribe(ctx)
+}
+
+// Publishes a change of the page, looking up its current title and revision
+func (rs *RepoService) publishChange(ctx context.Context, kind models.PageChangeKind, pageId uint) {
+	page, err := rs.getPageById(ctx, pageId)
+	if err != nil {
+		// NOTE: The change is already saved, the feed is best effort
+		log.Println(err)
+		return
+	}
+	rs.changes.publish(models.PageChange{
+		Kind:   kind,
+		PageId: page.PageId,
+		Title:  page.Title,
+		RevId:  page.LatestRev,
+		At:     time.Now().UTC(),
+	})
+}
@@ -0,0 +1,67 @@
+package reposervice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dev-mackan/gowiki/pkg/models"
+)
+
+// Receives until the subscription ends, returning the number of changes
+func drain(t *testing.T, sub *ChangeSubscription) int {
+	t.Helper()
+	received := 0
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-sub.Changes():
+			if !ok {
+				return received
+			}
+			received++
+		case <-timeout:
+			t.Fatal("subscription did not end")
+		}
+	}
+}
+
+func TestChangeFeedContextDone(t *testing.T) {
+	feed := newChangeFeed()
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := feed.subscribe(ctx)
+	feed.publish(models.PageChange{Kind: models.PageEdited, PageId: 1})
+	if change := <-sub.Changes(); change.PageId != 1 {
+		t.Fatalf("got change %+v", change)
+	}
+	cancel()
+	drain(t, sub)
+	if sub.Err() != nil {
+		t.Errorf("got error %v, want none", sub.Err())
+	}
+	// NOTE: Publishing after the subscription ended must not panic
+	feed.publish(models.PageChange{Kind: models.PageEdited, PageId: 2})
+}
+
+func TestChangeFeedFellBehind(t *testing.T) {
+	feed := newChangeFeed()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	slow := feed.subscribe(ctx)
+	for i := 0; i <= changeBuffer; i++ {
+		feed.publish(models.PageChange{Kind: models.PageEdited, PageId: uint(i)})
+	}
+	// Subscribers that keep up are not affected
+	fast := feed.subscribe(ctx)
+	feed.publish(models.PageChange{Kind: models.PageEdited})
+	if _, ok := <-fast.Changes(); !ok {
+		t.Fatal("fast subscriber was dropped")
+	}
+	if received := drain(t, slow); received != changeBuffer {
+		t.Errorf("received %d changes, want %d", received, changeBuffer)
+	}
+	if !errors.Is(slow.Err(), ErrChangesMissed) {
+		t.Errorf("got error %v, want %v", slow.Err(), ErrChangesMissed)
+	}
+}
@@ -1,22 +1,41 @@
 package reposervice
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
 
-type NotFoundError struct {
-	err string
-}
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+)
 
-func (e *NotFoundError) Error() string {
-	if e.err == "" {
-		return fmt.Sprintf("Resource not found")
-	}
-	return fmt.Sprintf(e.err)
-}
+const (
+	// Characters with a meaning in URLs, markdown links or HTML
+	reservedTitleChars = `#%?[]{}|<>\"`
+	maxTitleLength     = 255
+)
 
-type DatabaseError struct {
-	err string
+// Passes on errors from the repositories. Anything that is not
+// already a wiki error is treated as an internal database error.
+func handleErr(err error) error {
+	return wikierr.From(err)
 }
 
-func (e *DatabaseError) Error() string {
-	return fmt.Sprintf("%s", e.err)
+// Validates a title that has been through utils.SanitizeTitle
+func validateTitle(title string) error {
+	if title == "" {
+		return wikierr.New(wikierr.ValidationFailed, "The title must not be empty")
+	}
+	for _, seg := range strings.Split(title, "/") {
+		if seg == "." || seg == ".." {
+			return wikierr.New(wikierr.ValidationFailed, "The title cannot have . or .. between slashes")
+		}
+	}
+	if i := strings.IndexAny(title, reservedTitleChars); i >= 0 {
+		r, _ := utf8.DecodeRuneInString(title[i:])
+		return wikierr.New(wikierr.ValidationFailed, fmt.Sprintf("The title cannot contain any of %s, found %q", reservedTitleChars, r))
+	}
+	if utf8.RuneCountInString(title) > maxTitleLength {
+		return wikierr.New(wikierr.ValidationFailed, fmt.Sprintf("The title cannot be longer than %d characters", maxTitleLength))
+	}
+	return nil
 }
@@ -0,0 +1,33 @@
+package reposervice
+
+import (
+	"context"
+	"time"
+
+	"github.com/dev-mackan/gowiki/pkg/models"
+)
+
+// Returns the response stored for the key since the given time, or nil if there is none
+func (rs *RepoService) GetStoredResponse(ctx context.Context, key string, since time.Time) (*models.StoredResponse, error) {
+	res, err := rs.repo.Idempotency.Get(ctx, key, since)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	return res, nil
+}
+
+func (rs *RepoService) SaveStoredResponse(ctx context.Context, res *models.StoredResponse) error {
+	err := rs.repo.Idempotency.Save(ctx, res)
+	if err != nil {
+		return handleErr(err)
+	}
+	return nil
+}
+
+func (rs *RepoService) DeleteStoredResponses(ctx context.Context, before time.Time) error {
+	err := rs.repo.Idempotency.DeleteBefore(ctx, before)
+	if err != nil {
+		return handleErr(err)
+	}
+	return nil
+}
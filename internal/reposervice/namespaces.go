@@ -0,0 +1,85 @@
+package reposervice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dev-mackan/gowiki/internal/auth"
+	"github.com/dev-mackan/gowiki/internal/namespaces"
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+	"github.com/dev-mackan/gowiki/pkg/models"
+	"github.com/dev-mackan/gowiki/pkg/utils"
+)
+
+func (rs *RepoService) GetNamespaces() []models.Namespace {
+	return rs.namespaces.All()
+}
+
+// Lists the pages of a namespace, "" being the main namespace
+func (rs *RepoService) GetPagesInNamespace(ctx context.Context, namespace string) (*[]*models.Page, error) {
+	ns, err := rs.getNamespace(namespace)
+	if err != nil {
+		return nil, err
+	}
+	pages, err := rs.repo.Page.GetAllInNamespace(ctx, ns.Name)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	return pages, nil
+}
+
+func (rs *RepoService) getNamespace(name string) (*namespaces.Namespace, error) {
+	ns, ok := rs.namespaces.Get(name)
+	if !ok {
+		return nil, wikierr.New(wikierr.ValidationFailed, fmt.Sprintf("Unknown namespace %q", name))
+	}
+	return ns, nil
+}
+
+// Sanitises and validates a title. Returns the namespace of the title and
+// the title with the namespace spelled as configured, e.g. template:foo
+// becomes Template:foo.
+func (rs *RepoService) parseTitle(title string) (*namespaces.Namespace, string, error) {
+	title = utils.SanitizeTitle(title)
+	ns, name := rs.namespaces.Parse(title)
+	if ns.Name != "" && name == "" {
+		return nil, "", wikierr.New(wikierr.ValidationFailed, fmt.Sprintf("The title needs a name after %s:", ns.Name))
+	}
+	title = ns.Title(name)
+	if err := validateTitle(title); err != nil {
+		return nil, "", err
+	}
+	return ns, title, nil
+}
+
+// Checks that the token of the request may edit pages in the namespace
+func (rs *RepoService) checkEdit(ctx context.Context, ns *namespaces.Namespace) error {
+	if ns.CanEdit(auth.TokenFrom(ctx)) {
+		return nil
+	}
+	return wikierr.New(wikierr.Forbidden, fmt.Sprintf("Pages in the %s namespace can only be edited by its editors", ns.Name))
+}
+
+// Checks that the token of the request may edit the pages, by the
+// namespaces they are in now
+func (rs *RepoService) checkEditPages(ctx context.Context, pages ...*models.Page) error {
+	for _, page := range pages {
+		// NOTE: Pages of namespaces that are no longer configured are left open
+		ns, ok := rs.namespaces.Get(page.Namespace)
+		if !ok {
+			continue
+		}
+		if err := rs.checkEdit(ctx, ns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rs *RepoService) checkEditPageID(ctx context.Context, pageId uint) error {
+	page, err := rs.getPageById(ctx, pageId)
+	if err != nil {
+		return handleErr(err)
+	}
+	return rs.checkEditPages(ctx, page)
+}
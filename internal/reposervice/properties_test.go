@@ -0,0 +1,49 @@
+package reposervice
+
+import (
+	"testing"
+
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+)
+
+func TestParsePropertyFilter(t *testing.T) {
+	tests := []struct {
+		prop  string
+		name  string
+		value *string
+		code  wikierr.Code
+	}{
+		{"owner:alice", "owner", strPtr("alice"), ""},
+		{" owner : alice ", "owner", strPtr("alice"), ""},
+		{"owner:*", "owner", nil, ""},
+		{"owner:", "owner", strPtr(""), ""},
+		{"contact.email:ops@example.com:8080", "contact.email", strPtr("ops@example.com:8080"), ""},
+		{"owner", "", nil, wikierr.ValidationFailed},
+		{":alice", "", nil, wikierr.ValidationFailed},
+		{"", "", nil, wikierr.ValidationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prop, func(t *testing.T) {
+			filter, err := parsePropertyFilter(tt.prop)
+			if tt.code != "" {
+				if wikierr.CodeOf(err) != tt.code {
+					t.Fatalf("got error %v, want %s", err, tt.code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if filter.Name != tt.name {
+				t.Errorf("got name %q, want %q", filter.Name, tt.name)
+			}
+			if (filter.Value == nil) != (tt.value == nil) || (filter.Value != nil && *filter.Value != *tt.value) {
+				t.Errorf("got value %v, want %v", filter.Value, tt.value)
+			}
+		})
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
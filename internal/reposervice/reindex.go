@@ -0,0 +1,35 @@
+package reposervice
+
+import (
+	"context"
+	"log"
+
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+)
+
+// Indexes the latest revision of every page again, e.g. after upgrading a
+// database created before an index existed. Pages whose content cannot be
+// indexed, such as front matter that is not a YAML mapping, are logged and
+// skipped. Returns the number of pages indexed.
+func (rs *RepoService) ReindexPages(ctx context.Context) (int, error) {
+	pages, err := rs.repo.Page.GetAll(ctx)
+	if err != nil {
+		return 0, handleErr(err)
+	}
+	indexed := 0
+	for _, page := range *pages {
+		err := rs.repo.Bundled.ReindexPage(ctx, page.PageId)
+		if err == nil {
+			indexed++
+			continue
+		}
+		// NOTE: A page deleted since it was listed is not an error either
+		switch wikierr.CodeOf(err) {
+		case wikierr.ValidationFailed, wikierr.PageNotFound:
+			log.Printf("Skipping page %d %q: %v", page.PageId, page.Title, err)
+		default:
+			return indexed, handleErr(err)
+		}
+	}
+	return indexed, nil
+}
@@ -2,21 +2,36 @@ package reposervice
 
 import (
 	"context"
-	"database/sql"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dev-mackan/gowiki/internal/frontmatter"
+	"github.com/dev-mackan/gowiki/internal/namespaces"
 	"github.com/dev-mackan/gowiki/internal/repos"
+	"github.com/dev-mackan/gowiki/internal/wikierr"
 	"github.com/dev-mackan/gowiki/pkg/models"
 	"github.com/dev-mackan/gowiki/pkg/utils"
 )
 
+// Pages link to each other with markdown links to /pages/<title>
+const pageLinkPrefix = "/pages/"
+
 // Interface for the database
 // Takes care of bundling items when necessary
 type RepoService struct {
-	repo *repos.Repository
+	repo       *repos.Repository
+	namespaces *namespaces.Namespaces
+	changes    *changeFeed
 }
 
-func NewRepoService(repo *repos.Repository) *RepoService {
+func NewRepoService(repo *repos.Repository, namespaces *namespaces.Namespaces) *RepoService {
 	return &RepoService{
-		repo: repo,
+		repo:       repo,
+		namespaces: namespaces,
+		changes:    newChangeFeed(),
 	}
 }
 
@@ -73,6 +88,9 @@ func (rs *RepoService) GetBundledPageWithRev(ctx context.Context, title string,
 	if err != nil {
 		return nil, handleErr(err)
 	}
+	if rev.PageId != page.PageId {
+		return nil, wikierr.New(wikierr.RevisionNotFound, "Revision not found")
+	}
 
 	text, err := rs.getTextById(ctx, rev.TextId)
 	if err != nil {
@@ -83,35 +101,70 @@ func (rs *RepoService) GetBundledPageWithRev(ctx context.Context, title string,
 }
 
 func (rs *RepoService) DeleteBundledPage(ctx context.Context, pageId uint) error {
-	err := rs.repo.Bundled.DeleteBundle(ctx, pageId)
+	page, err := rs.getPageById(ctx, pageId)
+	if err != nil {
+		return handleErr(err)
+	}
+	if err = rs.checkEditPages(ctx, page); err != nil {
+		return err
+	}
+	err = rs.repo.Bundled.DeleteBundle(ctx, pageId)
 	if err != nil {
 		return handleErr(err)
 	}
+	rs.changes.publish(models.PageChange{
+		Kind:   models.PageDeleted,
+		PageId: page.PageId,
+		Title:  page.Title,
+		At:     time.Now().UTC(),
+	})
 	return nil
 }
 
-func (rs *RepoService) CreateBundledPage(ctx context.Context, title string, content string) error {
-	title = utils.SanitizeTitle(title)
-	err := rs.repo.Bundled.NewPageBundle(ctx, title, content)
+// Returns the id of the new page
+func (rs *RepoService) CreateBundledPage(ctx context.Context, title string, content string) (uint, error) {
+	ns, title, err := rs.parseTitle(title)
 	if err != nil {
-		return handleErr(err)
+		return 0, err
 	}
-	return nil
+	if err = rs.checkEdit(ctx, ns); err != nil {
+		return 0, err
+	}
+	pageId, err := rs.repo.Bundled.NewPageBundle(ctx, ns.Name, title, content)
+	if err != nil {
+		return 0, handleErr(err)
+	}
+	rs.publishChange(ctx, models.PageCreated, pageId)
+	return pageId, nil
 }
 
-func (rs *RepoService) UpdateBundledPage(ctx context.Context, pageId uint, title string, content string) error {
-	title = utils.SanitizeTitle(title)
-	err := rs.repo.Bundled.UpdateBundledPage(ctx, pageId, title, content)
+// Renames the page and publishes the content. With subpages the pages
+// below it are moved along, see UpdatePageTitle.
+func (rs *RepoService) UpdateBundledPage(ctx context.Context, pageId uint, title string, content string, subpages bool) error {
+	renames, err := rs.planRename(ctx, pageId, title, subpages)
+	if err != nil {
+		return err
+	}
+	err = rs.repo.Bundled.UpdateBundledPage(ctx, pageId, renames, content)
 	if err != nil {
 		return handleErr(err)
 	}
-	return err
+	rs.publishChange(ctx, models.PageEdited, pageId)
+	for _, rn := range renames[1:] {
+		rs.publishChange(ctx, models.PageRenamed, rn.PageId)
+	}
+	return nil
 }
+
 func (rs *RepoService) NewPageRev(ctx context.Context, pageId uint, content string) error {
+	if err := rs.checkEditPageID(ctx, pageId); err != nil {
+		return err
+	}
 	err := rs.repo.Bundled.UpdateBundledPageContent(ctx, pageId, content)
 	if err != nil {
 		return handleErr(err)
 	}
+	rs.publishChange(ctx, models.PageEdited, pageId)
 	return nil
 }
 
@@ -147,17 +200,422 @@ func (rs *RepoService) GetTextByRevID(ctx context.Context, revID uint) (*models.
 	return text, nil
 }
 
-func (rs *RepoService) UpdatePageTitle(ctx context.Context, pageId uint, title string) error {
-	title = utils.SanitizeTitle(title)
-	err := rs.repo.Page.UpdateTitle(ctx, pageId, title)
+// Renames the page. With subpages the pages below it are moved along,
+// e.g. Team/Service becomes Ops/Service when Team is renamed to Ops.
+func (rs *RepoService) UpdatePageTitle(ctx context.Context, pageId uint, title string, subpages bool) error {
+	renames, err := rs.planRename(ctx, pageId, title, subpages)
+	if err != nil {
+		return err
+	}
+	err = rs.repo.Bundled.RenamePages(ctx, renames)
 	if err != nil {
 		return handleErr(err)
 	}
+	for _, rn := range renames {
+		rs.publishChange(ctx, models.PageRenamed, rn.PageId)
+	}
 	return nil
 }
 
+// Returns the renames giving the page its new title, followed by those of
+// its subpages if they are moved along. Checks that every page may be edited.
+func (rs *RepoService) planRename(ctx context.Context, pageId uint, title string, subpages bool) ([]models.PageRename, error) {
+	ns, title, err := rs.parseTitle(title)
+	if err != nil {
+		return nil, err
+	}
+	if err = rs.checkEdit(ctx, ns); err != nil {
+		return nil, err
+	}
+	page, err := rs.getPageById(ctx, pageId)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	if err = rs.checkEditPages(ctx, page); err != nil {
+		return nil, err
+	}
+	renames := []models.PageRename{{PageId: page.PageId, Namespace: ns.Name, Title: title}}
+	if !subpages {
+		return renames, nil
+	}
+	below, err := rs.repo.Page.GetSubpages(ctx, []string{page.Title}, true)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	if err = rs.checkEditPages(ctx, *below...); err != nil {
+		return nil, err
+	}
+	// NOTE: Subpages match the title ignoring case, so the old prefix is
+	// cut by its number of segments rather than its length
+	depth := strings.Count(page.Title, "/") + 1
+	for _, sub := range *below {
+		rest := strings.SplitN(sub.Title, "/", depth+1)[depth]
+		subTitle := title + "/" + rest
+		if err = validateTitle(subTitle); err != nil {
+			return nil, err
+		}
+		renames = append(renames, models.PageRename{PageId: sub.PageId, Namespace: ns.Name, Title: subTitle})
+	}
+	return renames, nil
+}
+
+// Returns the pages below the page, only its children unless all is set
+func (rs *RepoService) GetSubpages(ctx context.Context, pageId uint, all bool) (*[]*models.Page, error) {
+	page, err := rs.getPageById(ctx, pageId)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	pages, err := rs.repo.Page.GetSubpages(ctx, []string{page.Title}, all)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	return pages, nil
+}
+
+// Returns the owners draft of a page. If baseRev is 0 the most recently
+// saved draft is returned, whatever revision it was based on.
+func (rs *RepoService) GetDraft(ctx context.Context, owner string, pageId uint, baseRev uint) (*models.Draft, error) {
+	var draft *models.Draft
+	var err error
+	if baseRev == 0 {
+		draft, err = rs.repo.Draft.GetLatest(ctx, owner, pageId)
+	} else {
+		draft, err = rs.repo.Draft.GetByBaseRev(ctx, owner, pageId, baseRev)
+	}
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	return draft, nil
+}
+
+func (rs *RepoService) SaveDraft(ctx context.Context, draft *models.Draft) error {
+	err := rs.repo.Draft.Save(ctx, draft)
+	if err != nil {
+		return handleErr(err)
+	}
+	return nil
+}
+
+func (rs *RepoService) DeleteDrafts(ctx context.Context, owner string, pageId uint) error {
+	err := rs.repo.Draft.DeleteByPageID(ctx, owner, pageId)
+	if err != nil {
+		return handleErr(err)
+	}
+	return nil
+}
+
+func (rs *RepoService) GetPageByID(ctx context.Context, pageId uint) (*models.Page, error) {
+	page, err := rs.getPageById(ctx, pageId)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	return page, nil
+}
+
+func (rs *RepoService) GetBundledPageByID(ctx context.Context, pageId uint) (*models.PageBundle, error) {
+	page, err := rs.getPageById(ctx, pageId)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+
+	rev, err := rs.getRevisionById(ctx, page.LatestRev)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+
+	text, err := rs.getTextById(ctx, rev.TextId)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+
+	return rs.buildPageBundle(page, rev, text), nil
+}
+
+func (rs *RepoService) GetPageRevsByID(ctx context.Context, pageId uint) (*[]*models.Revision, error) {
+	// NOTE: An unknown page should not look like a page without revisions
+	_, err := rs.getPageById(ctx, pageId)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	revs, err := rs.getRevsByPageID(ctx, pageId)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	return revs, nil
+}
+
+// Returns a revision bundled with its page and text, for when only the
+// revision is known
+func (rs *RepoService) GetBundledRevision(ctx context.Context, revId uint) (*models.PageBundle, error) {
+	rev, err := rs.getRevisionById(ctx, revId)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	page, err := rs.getPageById(ctx, rev.PageId)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	text, err := rs.getTextById(ctx, rev.TextId)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	return rs.buildPageBundle(page, rev, text), nil
+}
+
+// Returns a revision of a page together with its text
+func (rs *RepoService) GetRevisionBundle(ctx context.Context, pageId uint, revId uint) (*models.RevisionBundle, error) {
+	rev, err := rs.getRevisionById(ctx, revId)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	if rev.PageId != pageId {
+		return nil, wikierr.New(wikierr.RevisionNotFound, "Revision not found")
+	}
+	text, err := rs.getTextById(ctx, rev.TextId)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	return &models.RevisionBundle{Revision: *rev, Text: *text}, nil
+}
+
+// The batch getters below return what they find, ids that are not found
+// are left out rather than being an error.
+
+func (rs *RepoService) GetPagesByIDs(ctx context.Context, pageIds []uint) (*[]*models.Page, error) {
+	pages, err := rs.repo.Page.GetByIDs(ctx, pageIds)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	return pages, nil
+}
+
+func (rs *RepoService) GetRevisionsByIDs(ctx context.Context, revIds []uint) (*[]*models.Revision, error) {
+	revs, err := rs.repo.Revision.GetByIDs(ctx, revIds)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	return revs, nil
+}
+
+// Returns the revisions of all the pages, ordered by page and revision
+func (rs *RepoService) GetRevsByPageIDs(ctx context.Context, pageIds []uint) (*[]*models.Revision, error) {
+	revs, err := rs.repo.Revision.GetAllByPageIDs(ctx, pageIds)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	return revs, nil
+}
+
+func (rs *RepoService) GetTextsByIDs(ctx context.Context, textIds []uint) (*[]*models.Text, error) {
+	texts, err := rs.repo.Text.GetByIDs(ctx, textIds)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	return texts, nil
+}
+
+// Returns the pages with the titles that exist, in the order of the titles.
+// Titles are matched like a single page is looked up by its title.
+func (rs *RepoService) FindPagesByTitles(ctx context.Context, titles []string) (*[]*models.Page, error) {
+	canonical := make([]string, len(titles))
+	for i, title := range titles {
+		ns, name := rs.namespaces.Parse(utils.SanitizeTitle(title))
+		canonical[i] = ns.Title(name)
+	}
+	found, err := rs.GetPagesByTitles(ctx, canonical)
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]*models.Page, 0, len(found))
+	seen := make(map[uint]bool, len(found))
+	for _, title := range canonical {
+		if page, ok := found[title]; ok && !seen[page.PageId] {
+			seen[page.PageId] = true
+			pages = append(pages, page)
+		}
+	}
+	return &pages, nil
+}
+
+// Returns the pages with the titles, by title, ignoring case
+func (rs *RepoService) GetPagesByTitles(ctx context.Context, titles []string) (map[string]*models.Page, error) {
+	pages, err := rs.repo.Page.GetByTitles(ctx, titles)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	byKey := make(map[string]*models.Page, len(*pages))
+	for _, page := range *pages {
+		byKey[utils.TitleKey(page.Title)] = page
+	}
+	res := make(map[string]*models.Page, len(titles))
+	for _, title := range titles {
+		if page, ok := byKey[utils.TitleKey(title)]; ok {
+			res[title] = page
+		}
+	}
+	return res, nil
+}
+
+// Returns the children of the pages with the titles, by title
+func (rs *RepoService) GetChildrenByTitles(ctx context.Context, titles []string) (map[string][]*models.Page, error) {
+	pages, err := rs.repo.Page.GetSubpages(ctx, titles, false)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	byParent := make(map[string][]*models.Page)
+	for _, page := range *pages {
+		key := utils.TitleKey(page.ParentTitle())
+		byParent[key] = append(byParent[key], page)
+	}
+	res := make(map[string][]*models.Page, len(titles))
+	for _, title := range titles {
+		res[title] = byParent[utils.TitleKey(title)]
+	}
+	return res, nil
+}
+
+// Returns the pages whose title or latest content contains every word of
+// the query, ignoring case. A nil namespace searches every namespace.
+func (rs *RepoService) SearchPages(ctx context.Context, query string, namespace *string) (*[]*models.Page, error) {
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return nil, wikierr.New(wikierr.ValidationFailed, "Search query cannot be empty")
+	}
+	if namespace != nil {
+		ns, err := rs.getNamespace(*namespace)
+		if err != nil {
+			return nil, err
+		}
+		namespace = &ns.Name
+	}
+	pages, err := rs.repo.Page.Search(ctx, terms, namespace)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	return pages, nil
+}
+
+// Returns the pages whose latest revision has every property, written as
+// name:value, or name:* to match any value. A nil namespace matches
+// every namespace.
+func (rs *RepoService) GetPagesByProperties(ctx context.Context, props []string, namespace *string) (*[]*models.Page, error) {
+	filters := make([]models.PropertyFilter, len(props))
+	for i, prop := range props {
+		filter, err := parsePropertyFilter(prop)
+		if err != nil {
+			return nil, err
+		}
+		filters[i] = filter
+	}
+	if namespace != nil {
+		ns, err := rs.getNamespace(*namespace)
+		if err != nil {
+			return nil, err
+		}
+		namespace = &ns.Name
+	}
+	pages, err := rs.repo.Page.GetByProperties(ctx, filters, namespace)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	return pages, nil
+}
+
+// Parses a property filter written as name:value, or name:* to match any
+// value. A name alone is rejected, it is more likely a mistake than a filter.
+func parsePropertyFilter(prop string) (models.PropertyFilter, error) {
+	name, value, ok := strings.Cut(prop, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return models.PropertyFilter{}, wikierr.New(wikierr.ValidationFailed, fmt.Sprintf("Property %q must be written as name:value or name:*, e.g. owner:alice", prop))
+	}
+	filter := models.PropertyFilter{Name: name}
+	if value = strings.TrimSpace(value); value != "*" {
+		filter.Value = &value
+	}
+	return filter, nil
+}
+
+// Returns, for each title, the pages whose latest revision links to it
+func (rs *RepoService) GetBacklinks(ctx context.Context, titles []string) (map[string][]*models.Page, error) {
+	backlinks := make(map[string][]*models.Page, len(titles))
+	if len(titles) == 0 {
+		return backlinks, nil
+	}
+	// NOTE: One search for pages with any link, whatever the number of titles
+	candidates, err := rs.repo.Page.Search(ctx, []string{pageLinkPrefix}, nil)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	bundles, err := rs.latestBundles(ctx, *candidates)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	for _, title := range titles {
+		link := backlinkPattern(title)
+		key := utils.TitleKey(title)
+		for _, b := range bundles {
+			if utils.TitleKey(b.Page.Title) != key && link.MatchString(b.Text.Content) {
+				backlinks[title] = append(backlinks[title], b.Page)
+			}
+		}
+	}
+	return backlinks, nil
+}
+
+// Matches links to the title, written as is or percent-encoded. The title
+// has to end at something that cannot be part of a title, as \b only
+// knows ASCII. A "/" continues the title, it starts a subpage.
+func backlinkPattern(title string) *regexp.Regexp {
+	alts := regexp.QuoteMeta(title)
+	if escaped := url.PathEscape(title); escaped != title {
+		alts += "|" + regexp.QuoteMeta(escaped)
+	}
+	return regexp.MustCompile(`(?i)` + regexp.QuoteMeta(pageLinkPrefix) + `(?:` + alts + `)(?:$|[^\pL\pM\pN_%/])`)
+}
+
+// Bundles the pages with their latest revision and text
+func (rs *RepoService) latestBundles(ctx context.Context, pages []*models.Page) ([]*models.PageBundle, error) {
+	revIds := make([]uint, len(pages))
+	for i, page := range pages {
+		revIds[i] = page.LatestRev
+	}
+	revs, err := rs.repo.Revision.GetByIDs(ctx, revIds)
+	if err != nil {
+		return nil, err
+	}
+	revsById := make(map[uint]*models.Revision, len(*revs))
+	textIds := make([]uint, 0, len(*revs))
+	for _, rev := range *revs {
+		revsById[rev.RevId] = rev
+		textIds = append(textIds, rev.TextId)
+	}
+	texts, err := rs.repo.Text.GetByIDs(ctx, textIds)
+	if err != nil {
+		return nil, err
+	}
+	textsById := make(map[uint]*models.Text, len(*texts))
+	for _, text := range *texts {
+		textsById[text.TextId] = text
+	}
+	bundles := make([]*models.PageBundle, 0, len(pages))
+	for _, page := range pages {
+		rev, ok := revsById[page.LatestRev]
+		if !ok {
+			continue
+		}
+		text, ok := textsById[rev.TextId]
+		if !ok {
+			continue
+		}
+		bundles = append(bundles, rs.buildPageBundle(page, rev, text))
+	}
+	return bundles, nil
+}
+
 func (rs *RepoService) getPageIdByTitle(ctx context.Context, title string) (uint, error) {
-	return rs.repo.Page.GetIDByTitle(ctx, title)
+	ns, name := rs.namespaces.Parse(utils.SanitizeTitle(title))
+	return rs.repo.Page.GetIDByTitle(ctx, ns.Title(name))
 }
 
 func (rs *RepoService) getPageById(ctx context.Context, pageId uint) (*models.Page, error) {
@@ -177,16 +635,15 @@ func (rs *RepoService) getRevsByPageID(ctx context.Context, revId uint) (*[]*mod
 }
 
 func (rs *RepoService) buildPageBundle(page *models.Page, rev *models.Revision, text *models.Text) *models.PageBundle {
-	return &models.PageBundle{
-		Page:     page,
-		Revision: rev,
-		Text:     text,
+	// NOTE: Front matter saved before it was validated may not parse
+	props, err := frontmatter.Parse(text.Content)
+	if err != nil || props == nil {
+		props = map[string]any{}
 	}
-}
-
-func handleErr(err error) error {
-	if err == sql.ErrNoRows {
-		return &NotFoundError{err: err.Error()}
+	return &models.PageBundle{
+		Page:       page,
+		Revision:   rev,
+		Text:       text,
+		Properties: props,
 	}
-	return &DatabaseError{err: err.Error()}
 }
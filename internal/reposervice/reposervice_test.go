@@ -0,0 +1,31 @@
+package reposervice
+
+import "testing"
+
+func TestBacklinkPattern(t *testing.T) {
+	tests := []struct {
+		title string
+		text  string
+		want  bool
+	}{
+		{"Team", "[x](/pages/Team)", true},
+		{"Team", "[x](/pages/team)", true},
+		{"Team", "[x](/pages/Team#members)", true},
+		{"Team", "[x](/pages/Team/Service)", false},
+		{"Team", "[x](/pages/Teams)", false},
+		{"Team", "[x](/pages/Team_Leads)", false},
+		{"Team/Service", "[x](/pages/Team)", false},
+		{"Team/Service", "[x](/pages/Team/Service)", true},
+		{"Team/Service", "[x](/pages/Team%2FService#setup)", true},
+		{"Team/Service", "[x](/pages/Team/Service/Runbook)", false},
+		{"Größe", "[x](/pages/Gr%C3%B6%C3%9Fe)", true},
+		{"Größe", "[x](/pages/Größer)", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title+" "+tt.text, func(t *testing.T) {
+			if got := backlinkPattern(tt.title).MatchString(tt.text); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
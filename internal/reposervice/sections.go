@@ -0,0 +1,95 @@
+package reposervice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dev-mackan/gowiki/internal/markdown"
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+	"github.com/dev-mackan/gowiki/pkg/models"
+)
+
+// Lists the sections of the latest revision, without their content
+func (rs *RepoService) GetSections(ctx context.Context, pageId uint) ([]*models.Section, error) {
+	bundle, err := rs.GetBundledPageByID(ctx, pageId)
+	if err != nil {
+		return nil, err
+	}
+	sections := markdown.Sections(bundle.Text.Content)
+	res := make([]*models.Section, len(sections))
+	for i, s := range sections {
+		res[i] = buildSection(s, "", bundle.Revision.RevId)
+	}
+	return res, nil
+}
+
+// Returns a section of the latest revision, by index or heading path, see
+// markdown.FindSection
+func (rs *RepoService) GetSection(ctx context.Context, pageId uint, section string) (*models.Section, error) {
+	bundle, err := rs.GetBundledPageByID(ctx, pageId)
+	if err != nil {
+		return nil, err
+	}
+	s, err := findSection(bundle.Text.Content, section)
+	if err != nil {
+		return nil, err
+	}
+	return buildSection(s, bundle.Text.Content[s.Start:s.End], bundle.Revision.RevId), nil
+}
+
+// Replaces a section of the latest revision with content, publishing the
+// page as a new revision unless nothing changed. If baseRev is not 0 the
+// edit fails when the page has changed since that revision, as the
+// section may have moved.
+func (rs *RepoService) UpdateSection(ctx context.Context, pageId uint, section string, content string, baseRev uint) error {
+	if err := rs.checkEditPageID(ctx, pageId); err != nil {
+		return err
+	}
+	bundle, err := rs.GetBundledPageByID(ctx, pageId)
+	if err != nil {
+		return err
+	}
+	if baseRev != 0 && baseRev != bundle.Revision.RevId {
+		return wikierr.New(wikierr.EditConflict, fmt.Sprintf("The page has changed since revision %d", baseRev))
+	}
+	s, err := findSection(bundle.Text.Content, section)
+	if err != nil {
+		return err
+	}
+	// NOTE: The rest of the page is kept as it was in this revision, so
+	// publishing fails if another edit got in first
+	content = markdown.ReplaceSection(bundle.Text.Content, s, content)
+	if content == bundle.Text.Content {
+		return nil
+	}
+	err = rs.repo.Bundled.UpdateBundledPageContentFrom(ctx, pageId, bundle.Revision.RevId, content)
+	if err != nil {
+		return handleErr(err)
+	}
+	rs.publishChange(ctx, models.PageEdited, pageId)
+	return nil
+}
+
+func findSection(content string, section string) (markdown.Section, error) {
+	s, ok := markdown.FindSection(markdown.Sections(content), section)
+	if !ok {
+		return s, wikierr.New(wikierr.SectionNotFound, fmt.Sprintf("Section %q not found", section))
+	}
+	return s, nil
+}
+
+func buildSection(s markdown.Section, content string, revId uint) *models.Section {
+	path := s.Path
+	if path == nil {
+		path = []string{}
+	}
+	return &models.Section{
+		Index:       s.Index,
+		Level:       s.Level,
+		Heading:     s.Heading,
+		Path:        path,
+		Anchor:      s.Anchor,
+		TextContent: content,
+		RevId:       revId,
+	}
+}
@@ -0,0 +1,132 @@
+package reposervice
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/dev-mackan/gowiki/internal/transclusion"
+	"github.com/dev-mackan/gowiki/internal/wikierr"
+	"github.com/dev-mackan/gowiki/pkg/models"
+	"github.com/dev-mackan/gowiki/pkg/utils"
+)
+
+// Expands the template calls in content with the latest revisions of the
+// templates
+func (rs *RepoService) ExpandTemplates(ctx context.Context, content string) (*models.Expansion, error) {
+	missing := []string{}
+	expanded, templates, err := transclusion.Expand(ctx, content, func(ctx context.Context, name string) (*models.PageBundle, error) {
+		title := rs.templateTitle(name)
+		bundle, err := rs.loadTemplate(ctx, title)
+		if bundle == nil && err == nil && !slices.Contains(missing, title) {
+			missing = append(missing, title)
+		}
+		return bundle, err
+	})
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	if templates == nil {
+		templates = []*models.Page{}
+	}
+	return &models.Expansion{TextContent: expanded, Templates: templates, Missing: missing}, nil
+}
+
+// Returns nil if there is no page with the title
+func (rs *RepoService) loadTemplate(ctx context.Context, title string) (*models.PageBundle, error) {
+	bundle, err := rs.GetBundledPageByTitle(ctx, title)
+	if wikierr.CodeOf(err) == wikierr.PageNotFound {
+		return nil, nil
+	}
+	return bundle, err
+}
+
+// Returns the title of the page a template call refers to. Names without
+// a namespace are in the Template namespace, a leading ":" refers to a
+// page of the main namespace instead, e.g. {{:Footer}}.
+func (rs *RepoService) templateTitle(name string) string {
+	name, main := strings.CutPrefix(name, ":")
+	ns, name := rs.namespaces.Parse(utils.SanitizeTitle(name))
+	if ns.Name == "" && !main {
+		if tmpl, ok := rs.namespaces.Get("Template"); ok {
+			ns = tmpl
+		}
+	}
+	return ns.Title(name)
+}
+
+// Returns the pages using the page as a template, directly or through
+// other templates
+func (rs *RepoService) GetTemplateUsers(ctx context.Context, pageId uint) (*[]*models.Page, error) {
+	page, err := rs.getPageById(ctx, pageId)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	users, err := rs.GetTemplateUsersByTitles(ctx, []string{page.Title})
+	if err != nil {
+		return nil, err
+	}
+	pages := users[page.Title]
+	if pages == nil {
+		pages = []*models.Page{}
+	}
+	return &pages, nil
+}
+
+// Returns, for each title, the pages using it as a template, directly or
+// through other templates. The uses are read from the index of the
+// template calls of the latest revisions, one query per level of nesting.
+func (rs *RepoService) GetTemplateUsersByTitles(ctx context.Context, titles []string) (map[string][]*models.Page, error) {
+	users := make(map[string][]*models.Page, len(titles))
+	if len(titles) == 0 {
+		return users, nil
+	}
+	// NOTE: The names are resolved here, as the template a name refers to
+	// depends on the configured namespaces
+	names, err := rs.repo.Page.GetTemplateCallNames(ctx)
+	if err != nil {
+		return nil, handleErr(err)
+	}
+	// The names each template is called by, by the key of the template title
+	callNames := make(map[string][]string)
+	for _, name := range names {
+		key := utils.TitleKey(rs.templateTitle(name))
+		callNames[key] = append(callNames[key], name)
+	}
+	for _, title := range titles {
+		users[title], err = rs.templateUsers(ctx, callNames, utils.TitleKey(title))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return users, nil
+}
+
+// Walks the callers of the template breadth first, so every page is
+// listed once even if templates call each other
+func (rs *RepoService) templateUsers(ctx context.Context, callNames map[string][]string, key string) ([]*models.Page, error) {
+	var users []*models.Page
+	seen := map[string]bool{key: true}
+	level := []string{key}
+	for len(level) > 0 {
+		var names []string
+		for _, key := range level {
+			names = append(names, callNames[key]...)
+		}
+		callers, err := rs.repo.Page.GetByTemplateCalls(ctx, names)
+		if err != nil {
+			return nil, handleErr(err)
+		}
+		level = nil
+		for _, page := range *callers {
+			pageKey := utils.TitleKey(page.Title)
+			if seen[pageKey] {
+				continue
+			}
+			seen[pageKey] = true
+			users = append(users, page)
+			level = append(level, pageKey)
+		}
+	}
+	return users, nil
+}
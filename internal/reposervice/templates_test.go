@@ -0,0 +1,61 @@
+package reposervice
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/dev-mackan/gowiki/internal/namespaces"
+	"github.com/dev-mackan/gowiki/internal/repos"
+	"github.com/dev-mackan/gowiki/internal/testutil"
+)
+
+func TestGetTemplateUsersByTitles(t *testing.T) {
+	ctx := context.Background()
+	rs := NewRepoService(repos.NewSqlRepository(testutil.NewDB(t)), namespaces.New(namespaces.BuiltIn, nil))
+	pageIds := make(map[string]uint)
+	for _, page := range []struct{ title, content string }{
+		{"Template:Box", "box"},
+		{"Template:Card", "{{Box}}"},
+		{"Home", "{{ template:box }} {{Card}}"},
+		{"Footer", "footer"},
+		{"About", "{{:Footer}} `{{Box}}`"},
+		{"Template:A", "{{B}}"},
+		{"Template:B", "{{A}}"},
+	} {
+		pageId, err := rs.CreateBundledPage(ctx, page.title, page.content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pageIds[page.title] = pageId
+	}
+	check := func(title string, want []string) {
+		t.Helper()
+		users, err := rs.GetTemplateUsersByTitles(ctx, []string{title})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, page := range users[title] {
+			got = append(got, page.Title)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("users of %s: got %q, want %q", title, got, want)
+		}
+	}
+	check("Template:Box", []string{"Home", "Template:Card"})
+	check("Template:Card", []string{"Home"})
+	check("Footer", []string{"About"})
+	check("Template:A", []string{"Template:B"})
+	check("Home", nil)
+
+	// The index follows edits and deletes
+	if err := rs.NewPageRev(ctx, pageIds["Home"], "No templates"); err != nil {
+		t.Fatal(err)
+	}
+	check("Template:Box", []string{"Template:Card"})
+	if err := rs.DeleteBundledPage(ctx, pageIds["Template:Card"]); err != nil {
+		t.Fatal(err)
+	}
+	check("Template:Box", nil)
+}
@@ -0,0 +1,34 @@
+// Package testutil has fixtures shared by the tests of other packages
+package testutil
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Opens an empty in-memory database created with scripts/migrations.sql.
+// Each test gets its own database, which is dropped when the test ends.
+func NewDB(t testing.TB) *sql.DB {
+	t.Helper()
+	_, file, _, _ := runtime.Caller(0)
+	schema, err := os.ReadFile(filepath.Join(filepath.Dir(file), "../../scripts/migrations.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// NOTE: The in-memory database is dropped with its last connection
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	if _, err = db.Exec(string(schema)); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
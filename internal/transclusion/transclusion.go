@@ -0,0 +1,333 @@
+// Package transclusion expands template calls such as
+// {{Warning|text=Read this first}} in page content with the content of the
+// template page. Templates refer to their parameters as {{{text}}}, or
+// {{{text|default}}}, and to unnamed parameters by position, {{{1}}}.
+// Calls in code blocks and code spans are left as they are.
+package transclusion
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dev-mackan/gowiki/internal/frontmatter"
+	"github.com/dev-mackan/gowiki/pkg/models"
+	"github.com/dev-mackan/gowiki/pkg/utils"
+)
+
+const (
+	// How deep templates may call other templates
+	MaxDepth = 8
+	// Bounds the work of one expansion, e.g. templates calling many others
+	MaxCalls = 500
+)
+
+// Loads the latest revision of the page a call refers to, by the name
+// written in the call. Returns nil if there is no such page.
+type Loader func(ctx context.Context, name string) (*models.PageBundle, error)
+
+// Expands the template calls in content. Returns the expanded content and
+// the template pages it used. Missing templates, loops and calls beyond
+// the limits are replaced by an error message in the content.
+func Expand(ctx context.Context, content string, load Loader) (string, []*models.Page, error) {
+	e := &expander{
+		ctx:    ctx,
+		load:   load,
+		loaded: make(map[string]*models.PageBundle),
+		used:   make(map[uint]bool),
+	}
+	out, err := e.expand(content, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return out, e.templates, nil
+}
+
+// Returns the names of the templates called in content, without
+// expanding them
+func Calls(content string) []string {
+	var names []string
+	scan(content, func(c call) (string, error) {
+		names = append(names, c.name)
+		return "", nil
+	})
+	return names
+}
+
+type call struct {
+	name string
+	args map[string]string
+}
+
+type expander struct {
+	ctx  context.Context
+	load Loader
+	// By the case folded call name, nil for missing templates
+	loaded    map[string]*models.PageBundle
+	used      map[uint]bool
+	templates []*models.Page
+	calls     int
+}
+
+// stack holds the ids of the templates being expanded
+func (e *expander) expand(content string, stack []uint) (string, error) {
+	return scan(content, func(c call) (string, error) {
+		return e.call(c, stack)
+	})
+}
+
+func (e *expander) call(c call, stack []uint) (string, error) {
+	e.calls++
+	if e.calls > MaxCalls {
+		return failure("too many template calls", c.name), nil
+	}
+	if len(stack) >= MaxDepth {
+		return failure("templates nested too deep", c.name), nil
+	}
+	key := utils.TitleKey(c.name)
+	bundle, ok := e.loaded[key]
+	if !ok {
+		var err error
+		bundle, err = e.load(e.ctx, c.name)
+		if err != nil {
+			return "", err
+		}
+		e.loaded[key] = bundle
+	}
+	if bundle == nil {
+		return failure("no such template", c.name), nil
+	}
+	if slices.Contains(stack, bundle.Page.PageId) {
+		return failure("template loop", bundle.Page.Title), nil
+	}
+	if !e.used[bundle.Page.PageId] {
+		e.used[bundle.Page.PageId] = true
+		e.templates = append(e.templates, bundle.Page)
+	}
+	// NOTE: The properties of a template are its own, not the callers
+	body := substitute(frontmatter.Body(bundle.Text.Content), c.args)
+	return e.expand(body, append(stack, bundle.Page.PageId))
+}
+
+// Shown instead of a call that could not be expanded
+func failure(reason string, name string) string {
+	return fmt.Sprintf("**Template error:** %s `%s`", reason, strings.ReplaceAll(name, "`", ""))
+}
+
+var paramPattern = regexp.MustCompile(`\{\{\{([^{}|]+)(?:\|([^{}]*))?\}\}\}`)
+
+// Replaces the parameters in the template body with the arguments of the
+// call. Parameters without an argument or default are left as they are.
+func substitute(body string, args map[string]string) string {
+	return paramPattern.ReplaceAllStringFunc(body, func(m string) string {
+		sub := paramPattern.FindStringSubmatch(m)
+		if arg, ok := args[strings.TrimSpace(sub[1])]; ok {
+			return arg
+		}
+		if strings.Contains(m, "|") {
+			return sub[2]
+		}
+		return m
+	})
+}
+
+// Calls f for every template call in content outside code, replacing the
+// call with what f returns
+func scan(content string, f func(call) (string, error)) (string, error) {
+	code := codeSpans(content)
+	var b strings.Builder
+	last := 0
+	for i := 0; ; {
+		j := strings.Index(content[i:], "{{")
+		if j < 0 {
+			break
+		}
+		start := i + j
+		if end, ok := code.skip(start); ok {
+			i = end
+			continue
+		}
+		// Parameters of a template that got no argument
+		if strings.HasPrefix(content[start:], "{{{") {
+			end := strings.Index(content[start:], "}}}")
+			if end < 0 {
+				break
+			}
+			i = start + end + 3
+			continue
+		}
+		end := closingBraces(content, start)
+		if end < 0 {
+			break
+		}
+		c, ok := parseCall(content[start+2 : end-2])
+		if !ok {
+			i = start + 2
+			continue
+		}
+		out, err := f(c)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(content[last:start])
+		b.WriteString(out)
+		last, i = end, end
+	}
+	b.WriteString(content[last:])
+	return b.String(), nil
+}
+
+// Returns the end of the call starting at start, counting nested calls,
+// or -1 if it is not closed
+func closingBraces(content string, start int) int {
+	depth := 0
+	for i := start; i < len(content)-1; {
+		switch content[i : i+2] {
+		case "{{":
+			depth++
+			i += 2
+		case "}}":
+			depth--
+			i += 2
+			if depth == 0 {
+				return i
+			}
+		default:
+			i++
+		}
+	}
+	return -1
+}
+
+// Parses the inside of {{...}}, reporting false if it is not a call
+func parseCall(inner string) (call, bool) {
+	parts := splitArgs(inner)
+	name := strings.TrimSpace(parts[0])
+	if name == "" || strings.ContainsAny(name, "\n{}") {
+		return call{}, false
+	}
+	c := call{name: name, args: make(map[string]string, len(parts)-1)}
+	pos := 0
+	for _, part := range parts[1:] {
+		if key, value, ok := strings.Cut(part, "="); ok && !strings.Contains(key, "{{") {
+			c.args[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			continue
+		}
+		pos++
+		c.args[strconv.Itoa(pos)] = part
+	}
+	return c, true
+}
+
+// Splits the arguments of a call at the | that are not in nested calls
+func splitArgs(inner string) []string {
+	var parts []string
+	depth, last := 0, 0
+	for i := 0; i < len(inner); i++ {
+		switch {
+		case strings.HasPrefix(inner[i:], "{{"):
+			depth++
+			i++
+		case strings.HasPrefix(inner[i:], "}}"):
+			depth--
+			i++
+		case inner[i] == '|' && depth == 0:
+			parts = append(parts, inner[last:i])
+			last = i + 1
+		}
+	}
+	return append(parts, inner[last:])
+}
+
+type span struct{ start, end int }
+
+type spans []span
+
+// Reports the end of the span containing i
+func (s spans) skip(i int) (int, bool) {
+	for _, sp := range s {
+		if sp.start <= i && i < sp.end {
+			return sp.end, true
+		}
+	}
+	return 0, false
+}
+
+// Finds the fenced code blocks and code spans of markdown content
+func codeSpans(content string) spans {
+	var s spans
+	text := 0
+	fence, open := "", 0
+	for i := 0; i < len(content); {
+		end := strings.IndexByte(content[i:], '\n')
+		if end < 0 {
+			end = len(content)
+		} else {
+			end += i + 1
+		}
+		line := strings.TrimLeft(content[i:end], " ")
+		if fence == "" {
+			if f := fenceOf(line); f != "" && len(content[i:end])-len(line) < 4 {
+				s = append(s, inlineSpans(content, text, i)...)
+				fence, open = f, i
+			}
+		} else if strings.HasPrefix(line, fence) && strings.TrimSpace(strings.TrimLeft(line, fence[:1])) == "" {
+			s = append(s, span{open, end})
+			fence, text = "", end
+		}
+		i = end
+	}
+	if fence != "" {
+		return append(s, span{open, len(content)})
+	}
+	return append(s, inlineSpans(content, text, len(content))...)
+}
+
+// Returns the opening fence of a fenced code block, e.g. ```
+func fenceOf(line string) string {
+	for _, c := range []string{"`", "~"} {
+		n := len(line) - len(strings.TrimLeft(line, c))
+		if n >= 3 {
+			return strings.Repeat(c, n)
+		}
+	}
+	return ""
+}
+
+// Finds the code spans between start and end, a run of backticks up to
+// the next run of the same length
+func inlineSpans(content string, start int, end int) spans {
+	var s spans
+	for i := start; i < end; {
+		if content[i] != '`' {
+			i++
+			continue
+		}
+		n := backticks(content[i:end])
+		closed := false
+		for j := i + n; j < end; {
+			if content[j] != '`' {
+				j++
+				continue
+			}
+			m := backticks(content[j:end])
+			if m == n {
+				s = append(s, span{i, j + m})
+				i, closed = j+m, true
+				break
+			}
+			j += m
+		}
+		if !closed {
+			i += n
+		}
+	}
+	return s
+}
+
+func backticks(s string) int {
+	return len(s) - len(strings.TrimLeft(s, "`"))
+}
@@ -0,0 +1,150 @@
+package transclusion
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dev-mackan/gowiki/pkg/models"
+	"github.com/dev-mackan/gowiki/pkg/utils"
+)
+
+// Loads templates from a map of titles to content, counting the loads
+type mapLoader struct {
+	pages map[string]string
+	loads int
+}
+
+func (l *mapLoader) load(ctx context.Context, name string) (*models.PageBundle, error) {
+	l.loads++
+	titles := slices.Sorted(maps.Keys(l.pages))
+	for i, title := range titles {
+		if utils.TitleKey(title) == utils.TitleKey(name) {
+			return &models.PageBundle{
+				Page: &models.Page{PageId: uint(i + 1), Title: title},
+				Text: &models.Text{Content: l.pages[title]},
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+func expand(t *testing.T, pages map[string]string, content string) (string, []string) {
+	t.Helper()
+	l := &mapLoader{pages: pages}
+	out, used, err := Expand(context.Background(), content, l.load)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, page := range used {
+		titles = append(titles, page.Title)
+	}
+	return out, titles
+}
+
+func TestExpand(t *testing.T) {
+	pages := map[string]string{
+		"Box":      "[{{{title|Untitled}}}: {{{1}}} {{{2}}}]",
+		"Warning":  "---\nowner: ops\n---\n**{{{text}}}**",
+		"Outer":    "<{{Inner|{{{1}}}}}>",
+		"Inner":    "({{{1}}})",
+		"A":        "a {{B}}",
+		"B":        "b {{A}}",
+		"Self":     "self {{self}}",
+		"Unstable": "{{Missing}}",
+	}
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		used    []string
+	}{
+		{"no calls", "Just {text}", "Just {text}", nil},
+		{"named argument", "{{Warning|text=Read this}}", "**Read this**", []string{"Warning"}},
+		{"default", "{{Box}}", "[Untitled: {{{1}}} {{{2}}}]", []string{"Box"}},
+		{"positional arguments", "{{Box|one|title=T|two}}", "[T: one two]", []string{"Box"}},
+		{"empty default", "{{Box|}}", "[Untitled:  {{{2}}}]", []string{"Box"}},
+		{"case insensitive name", "{{box|x}}", "[Untitled: x {{{2}}}]", []string{"Box"}},
+		{"nested calls", "{{Outer|x}}", "<(x)>", []string{"Outer", "Inner"}},
+		{"call as argument", "{{Box|{{Inner|x}}}}", "[Untitled: (x) {{{2}}}]", []string{"Box", "Inner"}},
+		{"missing template", "{{Nope}}", "**Template error:** no such template `Nope`", nil},
+		{"missing nested template", "{{Unstable}}", "**Template error:** no such template `Missing`", []string{"Unstable"}},
+		{"loop", "{{A}}", "a b **Template error:** template loop `A`", []string{"A", "B"}},
+		{"calls itself", "{{Self}}", "self **Template error:** template loop `Self`", []string{"Self"}},
+		{"same template twice", "{{Inner|1}}{{Inner|2}}", "(1)(2)", []string{"Inner"}},
+		{"not a call", "{{ }} {{a\nb}}", "{{ }} {{a\nb}}", nil},
+		{"unclosed call", "{{Inner|x", "{{Inner|x", nil},
+		{"fenced code", "```\n{{Inner|x}}\n```\n{{Inner|y}}", "```\n{{Inner|x}}\n```\n(y)", []string{"Inner"}},
+		{"tilde fence", "~~~~\n{{Inner|x}}\n~~~\n~~~~\n", "~~~~\n{{Inner|x}}\n~~~\n~~~~\n", nil},
+		{"unclosed fence", "{{Inner|y}}\n```\n{{Inner|x}}\n", "(y)\n```\n{{Inner|x}}\n", []string{"Inner"}},
+		{"indented code is not a fence", "    ```\n{{Inner|x}}", "    ```\n(x)", []string{"Inner"}},
+		{"code span", "`{{Inner|x}}` {{Inner|y}}", "`{{Inner|x}}` (y)", []string{"Inner"}},
+		{"double backtick span", "``a ` {{Inner|x}}`` {{Inner|y}}", "``a ` {{Inner|x}}`` (y)", []string{"Inner"}},
+		{"unclosed backtick", "` {{Inner|x}}", "` (x)", []string{"Inner"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, used := expand(t, pages, tt.content)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(used, tt.used) {
+				t.Errorf("used %q, want %q", used, tt.used)
+			}
+		})
+	}
+}
+
+func TestExpandLoadsOnce(t *testing.T) {
+	l := &mapLoader{pages: map[string]string{"Inner": "x"}}
+	if _, _, err := Expand(context.Background(), "{{Inner}}{{inner}}{{Nope}}{{Nope}}", l.load); err != nil {
+		t.Fatal(err)
+	}
+	if l.loads != 2 {
+		t.Errorf("loaded %d times, want 2", l.loads)
+	}
+}
+
+func TestExpandMaxDepth(t *testing.T) {
+	// D1 calls D2 and so on, D9 is nested one level too deep
+	pages := make(map[string]string)
+	for i := 1; i <= MaxDepth+1; i++ {
+		pages[fmt.Sprintf("D%d", i)] = fmt.Sprintf("%d {{D%d}}", i, i+1)
+	}
+	got, used := expand(t, pages, "{{D1}}")
+	want := "1 2 3 4 5 6 7 8 **Template error:** templates nested too deep `D9`"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if len(used) != MaxDepth {
+		t.Errorf("used %d templates, want %d", len(used), MaxDepth)
+	}
+}
+
+func TestExpandMaxCalls(t *testing.T) {
+	pages := map[string]string{"T": "x"}
+	got, _ := expand(t, pages, strings.Repeat("{{T}}", MaxCalls+1))
+	want := strings.Repeat("x", MaxCalls) + "**Template error:** too many template calls `T`"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	// NOTE: Nested calls count too
+	pages["Twice"] = "{{T}}{{T}}"
+	got, _ = expand(t, pages, strings.Repeat("{{Twice}}", MaxCalls/3+1))
+	if n := strings.Count(got, "too many template calls"); n != 1 {
+		t.Errorf("got %d failed calls, want 1", n)
+	}
+}
+
+func TestCalls(t *testing.T) {
+	got := Calls("{{A}} `{{B}}` {{C|x={{D}}}} {{{param}}}\n```\n{{E}}\n```\n")
+	want := []string{"A", "C"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
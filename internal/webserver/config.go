@@ -1,15 +1,34 @@
 package webserver
 
 import (
+	"crypto/rand"
 	"log"
+	"net/http"
 	"os"
 	"path/filepath"
+	"time"
+
+	"github.com/dev-mackan/gowiki/internal/markdown"
+	"github.com/dev-mackan/gowiki/internal/middleware"
+	"github.com/dev-mackan/gowiki/internal/rendercache"
+	"github.com/dev-mackan/gowiki/pkg/utils"
 )
 
 type WebServerConfig struct {
-	listenAddr    string
-	apiAddr       string
+	listenAddr string
+	apiAddr    string
+	// Sent as a bearer token when the API requires one
+	apiToken   string
+	httpClient *http.Client
+	// API replies kept to revalidate instead of fetching them again
+	apiCacheSize  int
 	templatePaths string
+	markdown      *markdown.Config
+	renderCache   *rendercache.Config
+	// Key used to derive CSRF tokens from session ids
+	csrfKey       []byte
+	secureCookies bool
+	rateLimit     *middleware.RateLimitConfig
 }
 
 func DefaultWebServerConfig() *WebServerConfig {
@@ -20,9 +39,32 @@ func DefaultWebServerConfig() *WebServerConfig {
 	listenAddr := ":3001"
 	apiAddr := "http://localhost:3000/api/v1"
 	dir = filepath.Join(dir, "..", "web", "templates", "*.html")
+	rateLimit := middleware.DefaultRateLimitConfig()
+	rateLimit.ReadPaths = []string{"/preview"}
 	return &WebServerConfig{
-		listenAddr,
-		apiAddr,
-		dir,
+		listenAddr:    listenAddr,
+		apiAddr:       apiAddr,
+		apiToken:      os.Getenv("GOWIKI_API_TOKEN"),
+		httpClient:    &http.Client{Timeout: 10 * time.Second},
+		apiCacheSize:  utils.EnvInt("GOWIKI_API_CACHE_SIZE", 1000),
+		templatePaths: dir,
+		markdown:      markdown.DefaultConfig(),
+		renderCache:   rendercache.DefaultConfig(),
+		csrfKey:       csrfKeyFromEnv(),
+		secureCookies: os.Getenv("GOWIKI_SECURE_COOKIES") == "true",
+		rateLimit:     rateLimit,
+	}
+}
+
+// Uses GOWIKI_CSRF_KEY if set. Otherwise a random key is generated,
+// which invalidates all CSRF tokens when the server restarts.
+func csrfKeyFromEnv() []byte {
+	if key := os.Getenv("GOWIKI_CSRF_KEY"); key != "" {
+		return []byte(key)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		log.Fatal(err)
 	}
+	return key
 }
@@ -1,38 +1,61 @@
 package webserver
 
-type WebErrorResponse interface {
-	Error() string         // Response to client
-	InternalError() string // The actual error
-	Status() int           // http status code
-}
+import (
+	"errors"
+	"net/http"
 
-type FetchError struct {
-	InternalError string
-}
+	"github.com/dev-mackan/gowiki/pkg/client"
+)
 
-func (e *FetchError) Error() string {
-	return "Error fetching a resource."
-}
-func (e *FetchError) Status() int {
-	return 500
+// Error with a message that is safe to show to the user
+type StatusError struct {
+	status  int
+	message string
 }
 
-type ReadResourceError struct{}
+func NewStatusError(status int, message string) error {
+	return &StatusError{status, message}
+}
 
-func (e *ReadResourceError) Error() string {
-	return "Error reading a response"
+func (e *StatusError) Error() string {
+	return e.message
 }
 
-func (e *ReadResourceError) Status() int {
-	return 500
+func (e *StatusError) Status() int {
+	return e.status
 }
 
-type UnmarshalResourceError struct{}
+type ErrorTmplModel struct {
+	Status  int
+	Title   string
+	Message string
+}
 
-func (e *UnmarshalResourceError) Error() string {
-	return "Error parsing a resource"
+// Maps errors from the handlers and the API to what is shown on the error page
+func NewErrorTmplModel(err error) *ErrorTmplModel {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return newErrorTmplModel(statusErr.status, statusErr.message)
+	}
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusNotFound:
+			return newErrorTmplModel(http.StatusNotFound, apiErr.Message)
+		case http.StatusConflict, http.StatusUnprocessableEntity, http.StatusBadRequest:
+			return newErrorTmplModel(apiErr.StatusCode, apiErr.Message)
+		case http.StatusTooManyRequests:
+			return newErrorTmplModel(http.StatusServiceUnavailable, "The wiki is busy, please try again later.")
+		}
+		return newErrorTmplModel(http.StatusBadGateway, "The wiki backend failed to handle the request.")
+	}
+	return newErrorTmplModel(http.StatusInternalServerError, "Something went wrong. Please try again later.")
 }
 
-func (e *UnmarshalResourceError) Status() int {
-	return 500
+func newErrorTmplModel(status int, message string) *ErrorTmplModel {
+	return &ErrorTmplModel{
+		Status:  status,
+		Title:   http.StatusText(status),
+		Message: message,
+	}
 }
@@ -1,18 +1,83 @@
 package webserver
 
 import (
+	"html/template"
+	"strings"
+
+	"github.com/dev-mackan/gowiki/internal/frontmatter"
 	"github.com/dev-mackan/gowiki/pkg/models"
 )
 
 type PageTmplModel struct {
-	bundle      *models.PageBundle
-	htmlContent string
+	*models.PageBundle
+	// Sanitised HTML rendered from the markdown in Text
+	Content template.HTML
+	// The pages directly below this one
+	Children []models.Page
+	// The pages using this one as a template, only listed for pages of
+	// the Template namespace
+	UsedBy []models.Page
 }
 
-func NewPageTmplModel(b *models.PageBundle, htmlContent string) *PageTmplModel {
+func NewPageTmplModel(b *models.PageBundle, htmlContent template.HTML) *PageTmplModel {
 	return &PageTmplModel{
-		b,
-		htmlContent,
+		PageBundle: b,
+		Content:    htmlContent,
+	}
+}
+
+type Breadcrumb struct {
+	Title string
+	// The last part of the title, e.g. Service for Team/Service
+	Label string
+}
+
+// Returns the pages above this one, top first. They are linked whether
+// or not they exist.
+func (m *PageTmplModel) Breadcrumbs() []Breadcrumb {
+	segments := strings.Split(m.Page.Title, "/")
+	crumbs := make([]Breadcrumb, 0, len(segments)-1)
+	for i := range segments[:len(segments)-1] {
+		crumbs = append(crumbs, Breadcrumb{
+			Title: strings.Join(segments[:i+1], "/"),
+			Label: strings.ReplaceAll(segments[i], "_", " "),
+		})
+	}
+	return crumbs
+}
+
+type PropertyRow struct {
+	Name string
+	// The values of lists are joined by commas
+	Value string
+}
+
+// Returns the front matter of the page as rows of name and value, sorted
+// by name
+func (m *PageTmplModel) PropertyRows() []PropertyRow {
+	var rows []PropertyRow
+	for _, prop := range frontmatter.Flatten(m.Properties) {
+		if n := len(rows); n > 0 && rows[n-1].Name == prop.Name {
+			rows[n-1].Value += ", " + prop.Value
+			continue
+		}
+		rows = append(rows, PropertyRow{Name: prop.Name, Value: prop.Value})
+	}
+	return rows
+}
+
+type EditTmplModel struct {
+	*models.PageBundle
+	// Unpublished draft of the page, nil if there is none
+	Draft *models.Draft
+	// The section being edited, nil when editing the whole page
+	Section *models.Section
+}
+
+func NewEditTmplModel(b *models.PageBundle, draft *models.Draft) *EditTmplModel {
+	return &EditTmplModel{
+		PageBundle: b,
+		Draft:      draft,
 	}
 }
 
@@ -2,9 +2,11 @@ package webserver
 
 import (
 	"fmt"
+	"html/template"
 	"log"
 	"net/http"
-	"text/template"
+
+	"github.com/dev-mackan/gowiki/internal/middleware"
 )
 
 type Templates struct {
@@ -12,16 +14,35 @@ type Templates struct {
 }
 
 func newTemplate(template_glob_path string) *Templates {
+	// NOTE: Placeholder funcs, the real ones are bound per request in Render
+	funcs := template.FuncMap{
+		"csrfField": func() template.HTML { return "" },
+		// Slashes of subpage titles are escaped, see pageURL
+		"pagePath": pageURL,
+	}
 	return &Templates{
-		templates: template.Must(template.ParseGlob(template_glob_path)),
+		templates: template.Must(template.New("").Funcs(funcs).ParseGlob(template_glob_path)),
 	}
 }
 
-func (t *Templates) Render(w http.ResponseWriter, name string, status int, data interface{}) error {
+func (t *Templates) Render(w http.ResponseWriter, r *http.Request, name string, status int, data interface{}) error {
+	// The base templates are never executed, so they can always be cloned
+	tmpl, err := t.templates.Clone()
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	token := middleware.CSRFToken(r)
+	tmpl.Funcs(template.FuncMap{
+		"csrfField": func() template.HTML {
+			return template.HTML(fmt.Sprintf(`<input type="hidden" name="%s" value="%s">`,
+				middleware.CSRFFormField, template.HTMLEscapeString(token)))
+		},
+	})
 	w.Header().Add("Content-Type", "text/html")
 	w.WriteHeader(status)
 	fileName := fmt.Sprintf("%s.html", name)
-	err := t.templates.ExecuteTemplate(w, fileName, data)
+	err = tmpl.ExecuteTemplate(w, fileName, data)
 	if err != nil {
 		log.Println(err)
 		return err
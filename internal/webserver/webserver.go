@@ -1,40 +1,66 @@
 package webserver
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/dev-mackan/gowiki/internal/messages"
+	"github.com/dev-mackan/gowiki/internal/markdown"
 	"github.com/dev-mackan/gowiki/internal/middleware"
+	"github.com/dev-mackan/gowiki/internal/rendercache"
+	"github.com/dev-mackan/gowiki/pkg/client"
+	"github.com/dev-mackan/gowiki/pkg/messages"
 	"github.com/dev-mackan/gowiki/pkg/models"
 	"github.com/dev-mackan/gowiki/pkg/utils"
+	"html/template"
 	"io"
 	"log"
 	"net/http"
-	"strconv"
+	"net/url"
 	"strings"
 )
 
+// Pages of this namespace list the pages using them
+const templateNamespace = "Template"
+
 type WebServer struct {
 	listenAddr string
-	apiAddr    string
+	api        *client.Client
 	html       *Templates
+	markdown   *markdown.Renderer
+	// HTML of rendered revisions, see renderRevision
+	renderCache *rendercache.Cache
+	csrf        func(http.Handler) http.Handler
+	rateLimit   func(http.Handler) http.Handler
 }
 
 func NewWebServer(config *WebServerConfig) *WebServer {
+	api := client.NewClient(config.apiAddr, config.httpClient)
+	api.SetToken(config.apiToken)
+	api.SetCache(config.apiCacheSize)
 	return &WebServer{
 		config.listenAddr,
-		config.apiAddr,
+		api,
 		newTemplate(config.templatePaths),
+		markdown.NewRenderer(config.markdown),
+		rendercache.New(config.renderCache),
+		middleware.NewCSRFMiddleware(config.csrfKey, config.secureCookies),
+		middleware.NewRateLimitMiddleware(config.rateLimit),
 	}
 }
 
 func (s *WebServer) mount() *http.ServeMux {
 	fs := http.FileServer(http.Dir("web/templates/css"))
+	jsFs := http.FileServer(http.Dir("web/templates/js"))
 	logger := middleware.NewLoggerMiddleware("WEB-SERVER")
 	router := http.NewServeMux()
+	router.Handle("GET /static/css/highlight.css", http.HandlerFunc(s.highlightCSSHandler))
 	router.Handle("GET /static/css/", http.StripPrefix("/static/css/", fs))
+	router.Handle("GET /static/js/", http.StripPrefix("/static/js/", jsFs))
+	router.Handle("GET /metrics", http.HandlerFunc(s.metricsHandler))
+	router.Handle("POST /preview", logger(s.makeApiHandlerFunc(s.previewHandler)))
+	router.Handle("PUT /drafts/{page_id}", logger(s.makeApiHandlerFunc(s.saveDraftHandler)))
+	router.Handle("DELETE /drafts/{page_id}", logger(s.makeApiHandlerFunc(s.deleteDraftHandler)))
 	//router.Handle("GET /", logger(s.makeApiHandlerFunc(s.testHandler)))
 	router.Handle("GET /", logger(s.makeApiHandlerFunc(s.indexHandler)))
 	router.Handle("GET /pages", logger(s.makeApiHandlerFunc(s.indexHandler)))
@@ -49,82 +75,176 @@ func (s *WebServer) mount() *http.ServeMux {
 	router.Handle("GET /pages/{page_title}/revisions", logger(s.makeApiHandlerFunc(s.revisionsHandler)))
 	router.Handle("GET /pages/{page_title}/revisions/{rev_id}", logger(s.makeApiHandlerFunc(s.pageWithRevHandler)))
 	router.Handle("GET /pages/{page_title}/revisions/{rev_id}/raw.md", logger(s.makeApiHandlerFunc(s.rawTextHandler)))
+	// Links written as /pages/Team/Service rather than /pages/Team%2FService
+	router.Handle("GET /pages/{path...}", logger(http.HandlerFunc(subpageRedirectHandler)))
 	return router
 }
 
 func (s *WebServer) Run() error {
 	mux := s.mount()
 	log.Println("GOWIKI-WEB listening on: ", s.listenAddr)
-	return http.ListenAndServe(s.listenAddr, mux)
+	return http.ListenAndServe(s.listenAddr, s.rateLimit(s.csrf(mux)))
 }
 
 func (s *WebServer) testHandler(w http.ResponseWriter, r *http.Request) error {
-	return s.html.Render(w, "index", 200, nil)
+	return s.html.Render(w, r, "index", 200, nil)
 }
 
 func (s *WebServer) indexHandler(w http.ResponseWriter, r *http.Request) error {
-	url := fmt.Sprintf("%s/pages", s.apiAddr)
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	pagesBytes, err := readRespBytes(resp)
-	if err != nil {
-		return err
-	}
-	var pages []models.Page
-	err = json.Unmarshal(pagesBytes, &pages)
+	pages, err := s.api.GetPages(r.Context())
 	if err != nil {
 		return err
 	}
-	return s.html.Render(w, "index", 200, &pages)
+	return s.html.Render(w, r, "index", 200, &pages)
 }
 
 func (s *WebServer) pageHandler(w http.ResponseWriter, r *http.Request) error {
 	pageTitle := r.PathValue("page_title")
-	url := fmt.Sprintf("%s/bundled/%s", s.apiAddr, pageTitle)
-	resp, err := http.Get(url)
+	bundle, err := s.api.GetBundledPage(r.Context(), pageTitle)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
-	defer resp.Body.Close()
-	bundleBytes, err := readRespBytes(resp)
+	content, err := s.renderRevision(r.Context(), bundle, true)
 	if err != nil {
-		log.Println(err)
 		return err
 	}
-	var bundle models.PageBundle
-	err = json.Unmarshal(bundleBytes, &bundle)
+	model := NewPageTmplModel(bundle, content)
+	model.Children, err = s.api.GetChildren(r.Context(), bundle.Page.PageId)
 	if err != nil {
-		log.Println(err)
 		return err
 	}
-	mdBuf, err := MarkdownToHTML([]byte(bundle.Text.Content))
+	if bundle.Page.Namespace == templateNamespace {
+		model.UsedBy, err = s.api.GetTemplateUsers(r.Context(), bundle.Page.PageId)
+		if err != nil {
+			return err
+		}
+	}
+	return s.html.Render(w, r, "page", 200, model)
+}
+
+// Renders the revision in the bundle with the latest templates, with a link
+// to edit each section if editable. The HTML is cached by revision as long
+// as the templates it used are unchanged and missing ones still missing.
+func (s *WebServer) renderRevision(ctx context.Context, bundle *models.PageBundle, editable bool) (template.HTML, error) {
+	key := rendercache.Key{RevId: bundle.Revision.RevId, Version: s.markdown.Version()}
+	if editable {
+		// NOTE: The edit links contain the title, which changes when renamed
+		key.Variant = "edit:" + bundle.Page.Title
+	}
+	entry, ok := s.renderCache.Get(key, func(e *rendercache.Entry) bool {
+		return s.templatesUnchanged(ctx, e)
+	})
+	if ok {
+		return entry.HTML, nil
+	}
+	content := bundle.Text.Content
+	expansion, err := s.api.ExpandTemplates(ctx, content)
+	if err != nil {
+		return "", err
+	}
+	var html template.HTML
+	if editable {
+		doc, err := s.markdown.RenderEditable([]byte(expansion.TextContent), content, func(section int) string {
+			return fmt.Sprintf("%s/edit?section=%d", pageURL(bundle.Page.Title), section)
+		})
+		if err != nil {
+			return "", err
+		}
+		html = doc.HTML
+	} else {
+		html, err = s.markdown.MarkdownToHTML([]byte(expansion.TextContent))
+		if err != nil {
+			return "", err
+		}
+	}
+	entry = &rendercache.Entry{HTML: html, Missing: expansion.Missing}
+	for _, t := range expansion.Templates {
+		entry.Templates = append(entry.Templates, *t)
+	}
+	s.renderCache.Put(key, entry)
+	return html, nil
+}
+
+// Tells whether the templates of a cached rendering still have the same
+// latest revisions, and the missing ones are still missing
+func (s *WebServer) templatesUnchanged(ctx context.Context, e *rendercache.Entry) bool {
+	titles := make([]string, 0, len(e.Templates)+len(e.Missing))
+	for _, t := range e.Templates {
+		titles = append(titles, t.Title)
+	}
+	titles = append(titles, e.Missing...)
+	if len(titles) == 0 {
+		return true
+	}
+	pages, err := s.api.GetPagesByTitles(ctx, titles)
 	if err != nil {
-		return err
+		return false
+	}
+	byKey := make(map[string]models.Page, len(pages))
+	for _, page := range pages {
+		byKey[utils.TitleKey(page.Title)] = page
+	}
+	for _, t := range e.Templates {
+		page, ok := byKey[utils.TitleKey(t.Title)]
+		if !ok || page.PageId != t.PageId || page.LatestRev != t.LatestRev {
+			return false
+		}
 	}
-	bundle.Text.Content = mdBuf.String()
-	return s.html.Render(w, "page", 200, bundle)
+	for _, title := range e.Missing {
+		if _, ok := byKey[utils.TitleKey(title)]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Serves the counters of the server in the Prometheus text format
+func (s *WebServer) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	hits, misses := s.renderCache.Stats()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintf(w, "# HELP gowiki_render_cache_hits_total Revisions served from the render cache.\n")
+	fmt.Fprintf(w, "# TYPE gowiki_render_cache_hits_total counter\n")
+	fmt.Fprintf(w, "gowiki_render_cache_hits_total %d\n", hits)
+	fmt.Fprintf(w, "# HELP gowiki_render_cache_misses_total Revisions rendered because they were not cached.\n")
+	fmt.Fprintf(w, "# TYPE gowiki_render_cache_misses_total counter\n")
+	fmt.Fprintf(w, "gowiki_render_cache_misses_total %d\n", misses)
+}
+
+// Serves the stylesheet for highlighted code blocks, generated from the
+// configured style
+func (s *WebServer) highlightCSSHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	w.Write(s.markdown.HighlightCSS())
+}
+
+// Redirects a subpage path with unescaped slashes to the page
+func subpageRedirectHandler(w http.ResponseWriter, r *http.Request) {
+	path := r.PathValue("path")
+	if path == "" {
+		http.Redirect(w, r, "/pages", http.StatusMovedPermanently)
+		return
+	}
+	http.Redirect(w, r, pageURL(path), http.StatusMovedPermanently)
 }
 
 func (s *WebServer) rawTextHandler(w http.ResponseWriter, r *http.Request) error {
-	revId := r.PathValue("rev_id")
-	url := fmt.Sprintf("%s/revisions/%s/text/raw", s.apiAddr, revId)
-	resp, err := http.Get(url)
+	revId, err := parseUint(r.PathValue("rev_id"))
 	if err != nil {
-		log.Println(err)
 		return err
 	}
-	defer resp.Body.Close()
-	textBytes, err := readRespBytes(resp)
-	if err != nil {
-		log.Println("read", err)
-		return err
+	// NOTE: Revisions never change, so the API's caching applies as it is
+	text, headers, err := s.api.GetRawTextIfNoneMatch(r.Context(), revId, r.Header.Get("If-None-Match"))
+	if headers.ETag != "" {
+		w.Header().Set("ETag", headers.ETag)
+	}
+	if headers.CacheControl != "" {
+		w.Header().Set("Cache-Control", headers.CacheControl)
+	}
+	if errors.Is(err, client.ErrNotModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
 	}
-	var text models.Text
-	err = json.Unmarshal(textBytes, &text)
 	if err != nil {
 		log.Println(err)
 		return err
@@ -136,102 +256,131 @@ func (s *WebServer) rawTextHandler(w http.ResponseWriter, r *http.Request) error
 
 func (s *WebServer) pageWithRevHandler(w http.ResponseWriter, r *http.Request) error {
 	pageTitle := r.PathValue("page_title")
-	revId := r.PathValue("rev_id")
-	url := fmt.Sprintf("%s/bundled/%s/revisions/%s", s.apiAddr, pageTitle, revId)
-	resp, err := http.Get(url)
-	if err != nil {
-		log.Println(err)
-		return err
-	}
-	defer resp.Body.Close()
-	bundleBytes, err := readRespBytes(resp)
+	revId, err := parseUint(r.PathValue("rev_id"))
 	if err != nil {
-		log.Println(err)
 		return err
 	}
-	var bundle models.PageBundle
-	err = json.Unmarshal(bundleBytes, &bundle)
+	bundle, err := s.api.GetBundledPageWithRev(r.Context(), pageTitle, revId)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
-	mdBuf, err := MarkdownToHTML([]byte(bundle.Text.Content))
+	// NOTE: Only the latest revision can be edited by section
+	content, err := s.renderRevision(r.Context(), bundle, false)
 	if err != nil {
 		return err
 	}
-	bundle.Text.Content = mdBuf.String()
-	return s.html.Render(w, "page", 200, bundle)
+	return s.html.Render(w, r, "page", 200, NewPageTmplModel(bundle, content))
 }
 
 func (s *WebServer) revisionsHandler(w http.ResponseWriter, r *http.Request) error {
 	pageTitle := r.PathValue("page_title")
-	pageurl := fmt.Sprintf("%s/pages/%s", s.apiAddr, pageTitle)
-	revsurl := fmt.Sprintf("%s/pages/%s/revisions", s.apiAddr, pageTitle)
-	resp, err := http.Get(pageurl)
+	ctx := r.Context()
+	page, err := s.api.GetPage(ctx, pageTitle)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
-	defer resp.Body.Close()
-	body, err := readRespBytes(resp)
+	revs, err := s.api.GetPageRevisions(ctx, pageTitle)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
-	var page models.Page
-	err = json.Unmarshal(body, &page)
+	pageRevs := NewPageRevsTmplModel(page, &revs)
+	return s.html.Render(w, r, "revisions", 200, &pageRevs)
+}
+
+func (s *WebServer) editPageGETHandler(w http.ResponseWriter, r *http.Request) error {
+	pageTitle := r.PathValue("page_title")
+	// NOTE: The editor is prefilled with the content of the latest revision
+	bundle, err := s.api.GetBundledPage(r.Context(), pageTitle)
 	if err != nil {
+		log.Println(err)
 		return err
 	}
-	resp, err = http.Get(revsurl)
+	// NOTE: Sections are edited without drafts
+	if section := r.URL.Query().Get("section"); section != "" {
+		model := NewEditTmplModel(bundle, nil)
+		model.Section, err = s.api.GetSection(r.Context(), bundle.Page.PageId, section)
+		if err != nil {
+			return err
+		}
+		return s.html.Render(w, r, "edit", 200, model)
+	}
+	draft, err := s.fetchDraft(r, bundle.Page.PageId)
 	if err != nil {
+		// NOTE: The editor still works without the draft
 		log.Println(err)
+	}
+	return s.html.Render(w, r, "edit", 200, NewEditTmplModel(bundle, draft))
+}
+
+// Returns the latest draft of the page saved in this session, or nil if there is none
+func (s *WebServer) fetchDraft(r *http.Request, pageId uint) (*models.Draft, error) {
+	owner := middleware.SessionOwner(r)
+	if owner == "" {
+		return nil, nil
+	}
+	draft, err := s.api.GetDraft(r.Context(), owner, pageId, 0)
+	if client.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// Autosave target of the editor
+func (s *WebServer) saveDraftHandler(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUint(r.PathValue("page_id"))
+	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-	body, err = readRespBytes(resp)
+	rq, err := decodeJSON[messages.SaveDraftRequest](http.MaxBytesReader(w, r.Body, 10<<20))
 	if err != nil {
-		log.Println(err)
 		return err
 	}
-	var revs []models.Revision
-	err = json.Unmarshal(body, &revs)
+	err = s.api.SaveDraft(r.Context(), middleware.SessionOwner(r), pageId, rq.BaseRev, rq.TextContent)
 	if err != nil {
-		log.Println(err)
 		return err
 	}
-	pageRevs := NewPageRevsTmplModel(&page, &revs)
-	return s.html.Render(w, "revisions", 200, &pageRevs)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
 }
 
-func (s *WebServer) editPageGETHandler(w http.ResponseWriter, r *http.Request) error {
-	pageTitle := r.PathValue("page_title")
-	pageurl := fmt.Sprintf("%s/pages/%s", s.apiAddr, pageTitle)
-	//TODO: Should not use a default client here. A client should be part of the server config
-	resp, err := http.DefaultClient.Get(pageurl)
+func (s *WebServer) deleteDraftHandler(w http.ResponseWriter, r *http.Request) error {
+	pageId, err := parseUint(r.PathValue("page_id"))
 	if err != nil {
-		log.Println(err)
 		return err
 	}
-	defer resp.Body.Close()
-	body, err := readRespBytes(resp)
+	err = s.api.DeleteDrafts(r.Context(), middleware.SessionOwner(r), pageId)
 	if err != nil {
-		log.Println(err)
 		return err
 	}
-	var page models.Page
-	err = json.Unmarshal(body, &page)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// Renders markdown sent by the editor so it can be shown as a preview
+func (s *WebServer) previewHandler(w http.ResponseWriter, r *http.Request) error {
+	content, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 10<<20))
 	if err != nil {
-		log.Println(err)
 		return err
 	}
-	return s.html.Render(w, "edit", 200, &page)
+	html, err := s.api.Render(r.Context(), string(content))
+	if err != nil {
+		return err
+	}
+	// NOTE: The API has already sanitised the HTML
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, err = w.Write([]byte(html))
+	return err
 }
 
 func (s *WebServer) editPagePOSTHandler(w http.ResponseWriter, r *http.Request) error {
-	log.Println(r.Form)
 	pageIdStr := r.FormValue("page_id")
-	pageId, err := utils.ParseUintFromStr(pageIdStr)
+	pageId, err := parseUint(pageIdStr)
 	if err != nil {
 		log.Println(err)
 		return err
@@ -242,7 +391,7 @@ func (s *WebServer) editPagePOSTHandler(w http.ResponseWriter, r *http.Request)
 	} else if formAction == "editName" {
 		err = s.editPageTitleHelper(w, r, pageId)
 	} else {
-		err = errors.New(string(http.StatusNotFound))
+		err = NewStatusError(http.StatusBadRequest, "Unknown form action")
 	}
 	if err != nil {
 		log.Println(err)
@@ -252,147 +401,91 @@ func (s *WebServer) editPagePOSTHandler(w http.ResponseWriter, r *http.Request)
 }
 
 func (s *WebServer) editPageContentHelper(w http.ResponseWriter, r *http.Request, pageId uint) error {
-	fileBytes, err := parseContentFileFromForm(r)
-	if err != nil {
-		log.Println(err)
-		return err
-	}
-	reqStruct := messages.UpdatePageContentRequest{
-		PageId:      pageId,
-		TextContent: string(fileBytes),
-	}
-	reqBytes, err := json.Marshal(&reqStruct)
+	fileBytes, err := parseContentFromForm(r)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
-	reader := bytes.NewBuffer(reqBytes)
-	url := fmt.Sprintf("%s/pages/%d/update/content", s.apiAddr, reqStruct.PageId)
-	err = sendReq(http.MethodPut, url, reader)
-	if err != nil {
-		return err
+	// NOTE: Publishing removes the drafts of this session
+	if section := r.FormValue("section"); section != "" {
+		baseRev, err := parseUint(r.FormValue("base_rev"))
+		if err != nil {
+			return err
+		}
+		err = s.api.UpdateSection(r.Context(), pageId, section, string(fileBytes), baseRev, middleware.SessionOwner(r))
+		if err != nil {
+			return err
+		}
+	} else {
+		err = s.api.UpdatePageContent(r.Context(), pageId, string(fileBytes), middleware.SessionOwner(r))
+		if err != nil {
+			return err
+		}
 	}
 	pageTitle := r.PathValue("page_title")
-	redirectUrl := fmt.Sprintf("/pages/%s", utils.SanitizeTitle(pageTitle))
+	redirectUrl := pageURL(pageTitle)
 	http.Redirect(w, r, redirectUrl, http.StatusSeeOther)
 	return nil
 }
 
 func (s *WebServer) editPageTitleHelper(w http.ResponseWriter, r *http.Request, pageId uint) error {
 	newTitle := r.FormValue("page_title")
-	reqStruct := messages.UpdatePageTitleRequest{PageId: pageId, PageTitle: newTitle}
-	reqBytes, err := json.Marshal(&reqStruct)
-	if err != nil {
-		log.Println(err)
-		return err
-	}
-	reader := bytes.NewBuffer(reqBytes)
-	url := fmt.Sprintf("%s/pages/%d/update/title", s.apiAddr, reqStruct.PageId)
-	err = sendReq(http.MethodPut, url, reader)
+	err := s.api.UpdatePageTitle(r.Context(), pageId, newTitle, r.FormValue("move_subpages") == "on")
 	if err != nil {
 		return err
 	}
-	redirectUrl := fmt.Sprintf("/pages/%s", utils.SanitizeTitle(newTitle))
+	redirectUrl := pageURL(newTitle)
 	http.Redirect(w, r, redirectUrl, http.StatusSeeOther)
 	return nil
 }
 
 func (s *WebServer) newPageGETHandler(w http.ResponseWriter, r *http.Request) error {
-	return s.html.Render(w, "new", 200, nil)
+	return s.html.Render(w, r, "new", 200, nil)
 }
 
 func (s *WebServer) newPagePOSTHandler(w http.ResponseWriter, r *http.Request) error {
 	//TODO: Validate form values
-	fileBytes, err := parseContentFileFromForm(r)
+	fileBytes, err := parseContentFromForm(r)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
-	br := messages.NewBundleRequest{
-		PageTitle:   r.FormValue("page_title"),
-		TextContent: string(fileBytes),
+	pageTitle := r.FormValue("page_title")
+	if pageTitle == "" {
+		return NewStatusError(http.StatusUnprocessableEntity, "Provide a title")
 	}
-	brJson, err := json.Marshal(&br)
+	err = s.api.CreatePage(r.Context(), pageTitle, string(fileBytes))
 	if err != nil {
 		log.Println(err)
 		return err
 	}
-	if br.PageTitle == "" {
-		return fmt.Errorf("Provide a title")
-	}
-	reader := bytes.NewBuffer(brJson)
-	url := fmt.Sprintf("%s/bundled/new", s.apiAddr)
-	//TODO: Should not use a default client here. A client should be part of the server config
-	resp, err := http.DefaultClient.Post(url, "application/json", reader)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusCreated {
-		//TODO: Add a unique error here
-		log.Println(resp.StatusCode)
-		return errors.New(string(resp.StatusCode))
-	}
-	redirectUrl := fmt.Sprintf("/pages/%s", utils.SanitizeTitle(br.PageTitle))
+	redirectUrl := pageURL(pageTitle)
 	http.Redirect(w, r, redirectUrl, http.StatusSeeOther)
 	return nil
 }
 
 func (s *WebServer) deletePageGETHandler(w http.ResponseWriter, r *http.Request) error {
 	pageTitle := r.PathValue("page_title")
-	pageurl := fmt.Sprintf("%s/pages/%s", s.apiAddr, pageTitle)
-	//TODO: Should not use a default client here. A client should be part of the server config
-	resp, err := http.DefaultClient.Get(pageurl)
-	if err != nil {
-		log.Println(err)
-		return err
-	}
-	defer resp.Body.Close()
-	body, err := readRespBytes(resp)
-	if err != nil {
-		log.Println(err)
-		return err
-	}
-	var page models.Page
-	err = json.Unmarshal(body, &page)
+	page, err := s.api.GetPage(r.Context(), pageTitle)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
-	return s.html.Render(w, "delete", 200, &page)
+	return s.html.Render(w, r, "delete", 200, page)
 }
 
 func (s *WebServer) deletePagePOSTHandler(w http.ResponseWriter, r *http.Request) error {
 	pageIdStr := r.FormValue("page_id")
-	pageId, err := strconv.ParseUint(pageIdStr, 10, 64)
+	pageId, err := parseUint(pageIdStr)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
-	dr := messages.DeletePageRequest{PageId: uint(pageId)}
-	reqBytes, err := json.Marshal(&dr)
+	err = s.api.DeletePage(r.Context(), pageId)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
-	reader := bytes.NewBuffer(reqBytes)
-	url := fmt.Sprintf("%s/bundled/delete", s.apiAddr)
-	apiReq, err := http.NewRequest(http.MethodDelete, url, reader)
-	if err != nil {
-		return err
-	}
-	//TODO: Should not use a default client here. A client should be part of the server config
-	resp, err := http.DefaultClient.Do(apiReq)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		//TODO: Add a unique error here
-		log.Println(resp.StatusCode)
-		return errors.New(string(resp.StatusCode))
-	}
-
 	redirectUrl := fmt.Sprintf("/pages")
 	http.Redirect(w, r, redirectUrl, http.StatusSeeOther)
 	return nil
@@ -406,41 +499,34 @@ type webFunc func(http.ResponseWriter, *http.Request) error
 
 func (s *WebServer) makeApiHandlerFunc(f webFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(client.WithForwardedFor(r.Context(), middleware.ClientIP(r)))
 		if err := f(w, r); err != nil {
-			s.html.Render(w, "error", 500, err.Error())
+			log.Println(err)
+			model := NewErrorTmplModel(err)
+			s.html.Render(w, r, "error", model.Status, model)
 		}
 	}
 }
 
-func sendReq(method string, url string, reader io.Reader) error {
-	apiReq, err := http.NewRequest(method, url, reader)
-	if err != nil {
-		return err
-	}
-	//TODO: Should not use a default client here. A client should be part of the server config
-	resp, err := http.DefaultClient.Do(apiReq)
+// Parses ids from paths and forms, invalid ids are the users fault
+func parseUint(str string) (uint, error) {
+	val, err := utils.ParseUintFromStr(str)
 	if err != nil {
-		return err
+		return 0, NewStatusError(http.StatusBadRequest, err.Error())
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		//TODO: Add a unique error here
-		log.Println(resp.StatusCode)
-		return errors.New(string(resp.StatusCode))
-	}
-	return nil
+	return val, nil
 }
 
-func readRespBytes(resp *http.Response) ([]byte, error) {
-	if resp.StatusCode != http.StatusOK {
-		//TODO: implement custom error type for this case
-		return nil, errors.New(fmt.Sprintf("%d", resp.StatusCode))
-	}
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	return body, nil
+// Returns the path of a page, with the title as the API saves it. The
+// slashes of subpages are escaped so the title stays one path segment.
+func pageURL(title string) string {
+	return "/pages/" + url.PathEscape(utils.SanitizeTitle(title))
+}
+
+func decodeJSON[T any](r io.Reader) (T, error) {
+	var v T
+	err := json.NewDecoder(r).Decode(&v)
+	return v, err
 }
 
 func HasMarkdownSuffix(filename string) bool {
@@ -457,25 +543,32 @@ func IsMarkdownContent(contentType string) bool {
 	return true
 }
 
+// Reads the page content from an uploaded markdown file if one was provided,
+// otherwise from the editor textarea
+func parseContentFromForm(r *http.Request) ([]byte, error) {
+	fileBytes, err := parseContentFileFromForm(r)
+	if err == http.ErrMissingFile {
+		return []byte(r.FormValue("text_content")), nil
+	}
+	return fileBytes, err
+}
+
 func parseContentFileFromForm(r *http.Request) ([]byte, error) {
-	var fileBytes []byte
-	if file, handler, err := r.FormFile("content"); err == nil {
-		defer file.Close()
-		if !HasMarkdownSuffix(handler.Filename) {
-			return nil, errors.New("Invalid file type. Only .md files are allowed")
-		}
-		if !IsMarkdownContent(handler.Header.Get("Content-Type")) {
-			return nil, errors.New("Invalid file type. Only Markdown files are allowed")
-		}
-		fileBytes, err = io.ReadAll(file)
-		if err != nil {
-			return nil, errors.New("Error reading the file")
-		}
-	} else if err != http.ErrMissingFile {
-		//NOTE: Leave the TextContent field empty
-	} else {
+	file, handler, err := r.FormFile("content")
+	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
+	if !HasMarkdownSuffix(handler.Filename) {
+		return nil, NewStatusError(http.StatusUnprocessableEntity, "Invalid file type. Only .md files are allowed")
+	}
+	if !IsMarkdownContent(handler.Header.Get("Content-Type")) {
+		return nil, NewStatusError(http.StatusUnprocessableEntity, "Invalid file type. Only Markdown files are allowed")
+	}
+	fileBytes, err := io.ReadAll(file)
+	if err != nil {
+		return nil, errors.New("Error reading the file")
+	}
 	return fileBytes, nil
 }
 
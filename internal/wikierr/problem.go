@@ -0,0 +1,62 @@
+package wikierr
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// Problem details (RFC 7807) sent to HTTP clients on errors.
+// Code is one of the stable codes.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     Code   `json:"code"`
+}
+
+func HTTPStatus(code Code) int {
+	switch code {
+	case PageNotFound, RevisionNotFound, TextNotFound, DraftNotFound, SectionNotFound:
+		return http.StatusNotFound
+	case DuplicateTitle, EditConflict, RequestInProgress:
+		return http.StatusConflict
+	case ValidationFailed:
+		return http.StatusUnprocessableEntity
+	case BadRequest:
+		return http.StatusBadRequest
+	case Unauthorized:
+		return http.StatusUnauthorized
+	case Forbidden:
+		return http.StatusForbidden
+	case RateLimited:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func NewProblem(r *http.Request, err error) *Problem {
+	wErr := From(err)
+	status := HTTPStatus(wErr.Code)
+	return &Problem{
+		Type:     "urn:gowiki:problem:" + string(wErr.Code),
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   wErr.Message,
+		Instance: r.URL.Path,
+		Code:     wErr.Code,
+	}
+}
+
+// Replies with err as application/problem+json
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	problem := NewProblem(r, err)
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		log.Println(err)
+	}
+}
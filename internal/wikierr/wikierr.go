@@ -0,0 +1,74 @@
+package wikierr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Stable, machine readable error codes shared by the repositories,
+// the repo service and the API
+type Code string
+
+const (
+	PageNotFound     Code = "page_not_found"
+	RevisionNotFound Code = "revision_not_found"
+	TextNotFound     Code = "text_not_found"
+	DraftNotFound    Code = "draft_not_found"
+	SectionNotFound  Code = "section_not_found"
+	DuplicateTitle   Code = "duplicate_title"
+	EditConflict     Code = "edit_conflict"
+	ValidationFailed Code = "validation_failed"
+	BadRequest       Code = "bad_request"
+	// The request has no accepted bearer token
+	Unauthorized Code = "unauthorized"
+	Forbidden    Code = "forbidden"
+	// A request with the same Idempotency-Key has not finished yet
+	RequestInProgress Code = "request_in_progress"
+	RateLimited       Code = "rate_limited"
+	Internal          Code = "internal"
+)
+
+type Error struct {
+	Code Code
+	// Safe to show to clients
+	Message string
+	// The underlying error, if any. Never shown to clients.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func New(code Code, message string) error {
+	return &Error{Code: code, Message: message}
+}
+
+func Wrap(err error, code Code, message string) error {
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+// Returns the code of err, or Internal if err is not an *Error
+func CodeOf(err error) Code {
+	var wErr *Error
+	if errors.As(err, &wErr) {
+		return wErr.Code
+	}
+	return Internal
+}
+
+// Returns err as an *Error, wrapping it as an internal error if needed
+func From(err error) *Error {
+	var wErr *Error
+	if errors.As(err, &wErr) {
+		return wErr
+	}
+	return &Error{Code: Internal, Message: "Internal server error", Err: err}
+}
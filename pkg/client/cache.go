@@ -0,0 +1,76 @@
+package client
+
+import (
+	"container/list"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// Returned by conditional requests when the API replies 304 Not Modified
+var ErrNotModified = errors.New("not modified")
+
+// The headers of a reply that tell how it may be cached
+type CacheHeaders struct {
+	ETag         string
+	CacheControl string
+}
+
+func cacheHeaders(header http.Header) CacheHeaders {
+	return CacheHeaders{
+		ETag:         header.Get("ETag"),
+		CacheControl: header.Get("Cache-Control"),
+	}
+}
+
+// Keeps replies with an ETag so they can be revalidated with If-None-Match
+// instead of fetched again. The least recently used are dropped first.
+type replyCache struct {
+	size int
+	mu   sync.Mutex
+	// Most recently used first
+	order *list.List
+	items map[string]*list.Element
+}
+
+type cachedReply struct {
+	url  string
+	etag string
+	body []byte
+}
+
+func newReplyCache(size int) *replyCache {
+	return &replyCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (c *replyCache) get(url string) *cachedReply {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[url]
+	if !ok {
+		return nil
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cachedReply)
+}
+
+func (c *replyCache) put(url string, etag string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	reply := &cachedReply{url, etag, body}
+	if el, ok := c.items[url]; ok {
+		el.Value = reply
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[url] = c.order.PushFront(reply)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cachedReply).url)
+	}
+}
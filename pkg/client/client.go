@@ -0,0 +1,379 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dev-mackan/gowiki/pkg/messages"
+	"github.com/dev-mackan/gowiki/pkg/models"
+)
+
+// Client for the gowiki /api/v1 endpoints
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+	// nil unless enabled with SetCache
+	cache *replyCache
+}
+
+// baseURL is the address of the API including the version prefix,
+// e.g. "http://localhost:3000/api/v1". http.DefaultClient is used if
+// httpClient is nil.
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		baseURL:    baseURL,
+		httpClient: httpClient,
+	}
+}
+
+// Sends token as a bearer token with every request, an empty token disables it
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// Keeps up to size replies with an ETag and revalidates them with
+// If-None-Match, so unchanged replies are not sent again. 0 disables it.
+func (c *Client) SetCache(size int) {
+	c.cache = nil
+	if size > 0 {
+		c.cache = newReplyCache(size)
+	}
+}
+
+type forwardedForContextKey struct{}
+
+// Returns a context whose requests tell the API they were made on behalf of
+// the client with ip, so the API rate limits that client rather than the
+// caller. The API only believes it from its trusted proxies.
+func WithForwardedFor(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, forwardedForContextKey{}, ip)
+}
+
+func (c *Client) GetPages(ctx context.Context) ([]models.Page, error) {
+	var pages []models.Page
+	err := c.do(ctx, http.MethodGet, "/pages", nil, nil, &pages)
+	return pages, err
+}
+
+// Returns the pages with the titles in one request, titles without a page
+// are left out
+func (c *Client) GetPagesByTitles(ctx context.Context, titles []string) ([]models.Page, error) {
+	var pages []models.Page
+	err := c.do(ctx, http.MethodGet, "/pages?"+url.Values{"title": titles}.Encode(), nil, nil, &pages)
+	return pages, err
+}
+
+func (c *Client) GetPage(ctx context.Context, title string) (*models.Page, error) {
+	var page models.Page
+	err := c.do(ctx, http.MethodGet, "/pages/"+url.PathEscape(title), nil, nil, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Returns the page with its latest revision and text
+func (c *Client) GetBundledPage(ctx context.Context, title string) (*models.PageBundle, error) {
+	var bundle models.PageBundle
+	err := c.do(ctx, http.MethodGet, "/bundled/"+url.PathEscape(title), nil, nil, &bundle)
+	if err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+func (c *Client) GetBundledPageWithRev(ctx context.Context, title string, revId uint) (*models.PageBundle, error) {
+	var bundle models.PageBundle
+	path := fmt.Sprintf("/bundled/%s/revisions/%d", url.PathEscape(title), revId)
+	err := c.do(ctx, http.MethodGet, path, nil, nil, &bundle)
+	if err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+// Returns a revision with its page and text
+func (c *Client) GetBundledRevision(ctx context.Context, revId uint) (*models.PageBundle, error) {
+	var bundle models.PageBundle
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/revisions/%d", revId), nil, nil, &bundle)
+	if err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+func (c *Client) GetPageRevisions(ctx context.Context, title string) ([]models.Revision, error) {
+	var revs []models.Revision
+	err := c.do(ctx, http.MethodGet, "/pages/"+url.PathEscape(title)+"/revisions", nil, nil, &revs)
+	return revs, err
+}
+
+// Returns the pages directly below the page
+func (c *Client) GetChildren(ctx context.Context, pageId uint) ([]models.Page, error) {
+	var pages []models.Page
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/pages/%d/children", pageId), nil, nil, &pages)
+	return pages, err
+}
+
+// Returns the pages using the page as a template
+func (c *Client) GetTemplateUsers(ctx context.Context, pageId uint) ([]models.Page, error) {
+	var pages []models.Page
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/pages/%d/used-by", pageId), nil, nil, &pages)
+	return pages, err
+}
+
+func (c *Client) GetRawText(ctx context.Context, revId uint) (*models.Text, error) {
+	var text models.Text
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/revisions/%d/text/raw", revId), nil, nil, &text)
+	if err != nil {
+		return nil, err
+	}
+	return &text, nil
+}
+
+// Like GetRawText, but returns ErrNotModified if the text has one of the
+// ETags in ifNoneMatch. The cache headers of the reply are returned either
+// way, so they can be passed on.
+func (c *Client) GetRawTextIfNoneMatch(ctx context.Context, revId uint, ifNoneMatch string) (*models.Text, CacheHeaders, error) {
+	header := http.Header{}
+	if ifNoneMatch != "" {
+		header.Set("If-None-Match", ifNoneMatch)
+	}
+	var text models.Text
+	respHeader, err := c.doWithHeaders(ctx, http.MethodGet, fmt.Sprintf("/revisions/%d/text/raw", revId), header, nil, &text)
+	if err != nil {
+		return nil, cacheHeaders(respHeader), err
+	}
+	return &text, cacheHeaders(respHeader), nil
+}
+
+func (c *Client) CreatePage(ctx context.Context, title string, content string) error {
+	rq := messages.NewBundleRequest{PageTitle: title, TextContent: content}
+	return c.do(ctx, http.MethodPost, "/bundled/new", nil, &rq, nil)
+}
+
+func (c *Client) DeletePage(ctx context.Context, pageId uint) error {
+	rq := messages.DeletePageRequest{PageId: pageId}
+	return c.do(ctx, http.MethodDelete, "/bundled/delete", nil, &rq, nil)
+}
+
+// Renames the page. With moveSubpages the pages below it are renamed too,
+// e.g. Team/Service becomes Ops/Service when Team is renamed to Ops.
+func (c *Client) UpdatePageTitle(ctx context.Context, pageId uint, title string, moveSubpages bool) error {
+	rq := messages.UpdatePageTitleRequest{PageId: pageId, PageTitle: title, MoveSubpages: moveSubpages}
+	return c.do(ctx, http.MethodPut, fmt.Sprintf("/pages/%d/update/title", pageId), nil, &rq, nil)
+}
+
+// Creates a new revision of the page. The drafts of draftOwner are removed
+// once the revision is published, draftOwner may be empty.
+func (c *Client) UpdatePageContent(ctx context.Context, pageId uint, content string, draftOwner string) error {
+	rq := messages.UpdatePageContentRequest{PageId: pageId, TextContent: content}
+	return c.do(ctx, http.MethodPut, fmt.Sprintf("/pages/%d/update/content", pageId), draftHeader(draftOwner), &rq, nil)
+}
+
+// Returns a section of the latest revision by index, e.g. "2", or heading
+// path, e.g. "Install/Linux"
+func (c *Client) GetSection(ctx context.Context, pageId uint, section string) (*models.Section, error) {
+	var s models.Section
+	err := c.do(ctx, http.MethodGet, sectionPath(pageId, section), nil, nil, &s)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Replaces a section of the latest revision, creating a new revision. If
+// baseRev is not 0 the edit fails with a conflict when the page has changed
+// since that revision.
+func (c *Client) UpdateSection(ctx context.Context, pageId uint, section string, content string, baseRev uint, draftOwner string) error {
+	rq := messages.UpdateSectionRequest{TextContent: content, BaseRev: baseRev}
+	return c.do(ctx, http.MethodPut, sectionPath(pageId, section), draftHeader(draftOwner), &rq, nil)
+}
+
+func sectionPath(pageId uint, section string) string {
+	parts := strings.Split(section, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return fmt.Sprintf("/pages/%d/sections/%s", pageId, strings.Join(parts, "/"))
+}
+
+// Renders markdown to sanitised HTML
+func (c *Client) Render(ctx context.Context, content string) (string, error) {
+	rq := messages.RenderRequest{TextContent: content}
+	var reply messages.RenderReply
+	err := c.do(ctx, http.MethodPost, "/render", nil, &rq, &reply)
+	return reply.Html, err
+}
+
+// Expands the template calls in markdown with the latest revisions of the
+// templates
+func (c *Client) ExpandTemplates(ctx context.Context, content string) (*models.Expansion, error) {
+	rq := messages.RenderRequest{TextContent: content}
+	var expansion models.Expansion
+	err := c.do(ctx, http.MethodPost, "/expand", nil, &rq, &expansion)
+	if err != nil {
+		return nil, err
+	}
+	return &expansion, nil
+}
+
+// Returns the owners draft of a page. If baseRev is 0 the most recently
+// saved draft is returned.
+func (c *Client) GetDraft(ctx context.Context, owner string, pageId uint, baseRev uint) (*models.Draft, error) {
+	path := fmt.Sprintf("/pages/%d/draft", pageId)
+	if baseRev != 0 {
+		path += fmt.Sprintf("?base_rev=%d", baseRev)
+	}
+	var draft models.Draft
+	err := c.do(ctx, http.MethodGet, path, draftHeader(owner), nil, &draft)
+	if err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+func (c *Client) SaveDraft(ctx context.Context, owner string, pageId uint, baseRev uint, content string) error {
+	rq := messages.SaveDraftRequest{BaseRev: baseRev, TextContent: content}
+	return c.do(ctx, http.MethodPut, fmt.Sprintf("/pages/%d/draft", pageId), draftHeader(owner), &rq, nil)
+}
+
+func (c *Client) DeleteDrafts(ctx context.Context, owner string, pageId uint) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/pages/%d/draft", pageId), draftHeader(owner), nil, nil)
+}
+
+// Runs the operations of rq in one transaction. If one fails nothing is
+// saved, the reply has the result of every operation and the error is an
+// APIError with the code and message of the failed operation.
+func (c *Client) Batch(ctx context.Context, rq messages.BatchRequest) (*messages.BatchReply, error) {
+	var reply messages.BatchReply
+	err := c.do(ctx, http.MethodPost, "/batch", nil, rq, &reply)
+	if err == nil {
+		return &reply, nil
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || json.Unmarshal(apiErr.body, &reply) != nil || len(reply.Results) == 0 {
+		return nil, err
+	}
+	for _, result := range reply.Results {
+		if result.Error != nil {
+			apiErr.Code = result.Error.Code
+			apiErr.Message = result.Error.Message
+		}
+	}
+	return &reply, err
+}
+
+func draftHeader(owner string) http.Header {
+	header := http.Header{}
+	if owner != "" {
+		header.Set(messages.DraftOwnerHeader, owner)
+	}
+	return header
+}
+
+// Sends a request with rq as the JSON body and decodes the JSON reply into
+// reply. Either may be nil.
+func (c *Client) do(ctx context.Context, method string, path string, header http.Header, rq any, reply any) error {
+	_, err := c.doWithHeaders(ctx, method, path, header, rq, reply)
+	return err
+}
+
+// Like do, but also returns the headers of the reply. GET requests are
+// revalidated from the cache unless header has its own If-None-Match, in
+// which case a 304 reply is returned as ErrNotModified.
+func (c *Client) doWithHeaders(ctx context.Context, method string, path string, header http.Header, rq any, reply any) (http.Header, error) {
+	var body io.Reader
+	if rq != nil {
+		rqBytes, err := json.Marshal(rq)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(rqBytes)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if rq != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if ip, _ := ctx.Value(forwardedForContextKey{}).(string); ip != "" {
+		req.Header.Set("X-Forwarded-For", ip)
+	}
+	var cached *cachedReply
+	if c.cache != nil && method == http.MethodGet && reply != nil && header.Get("If-None-Match") == "" {
+		cached = c.cache.get(req.URL.String())
+		if cached != nil {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var replyBytes []byte
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		replyBytes = cached.body
+	case resp.StatusCode == http.StatusNotModified:
+		return resp.Header, ErrNotModified
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return resp.Header, newAPIError(req, resp)
+	case reply == nil:
+		return resp.Header, nil
+	default:
+		replyBytes, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.Header, fmt.Errorf("%s %s: reading reply: %w", method, req.URL, err)
+		}
+		if etag := resp.Header.Get("ETag"); etag != "" && c.cache != nil && method == http.MethodGet {
+			c.cache.put(req.URL.String(), etag, replyBytes)
+		}
+	}
+	if err := json.Unmarshal(replyBytes, reply); err != nil {
+		return resp.Header, fmt.Errorf("%s %s: decoding reply: %w", method, req.URL, err)
+	}
+	return resp.Header, nil
+}
+
+func newAPIError(req *http.Request, resp *http.Response) *APIError {
+	apiErr := &APIError{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+	}
+	var problem struct {
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+		Code   string `json:"code"`
+	}
+	apiErr.body, _ = io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err := json.Unmarshal(apiErr.body, &problem); err == nil {
+		apiErr.Code = problem.Code
+		apiErr.Message = problem.Detail
+		if apiErr.Message == "" {
+			apiErr.Message = problem.Title
+		}
+	}
+	return apiErr
+}
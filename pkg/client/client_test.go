@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dev-mackan/gowiki/pkg/messages"
+)
+
+func TestBatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		statuses []string
+		code     string
+	}{
+		{"committed", 200,
+			`{"committed":true,"dry_run":false,"results":[{"status":"ok","page_id":1,"title":"Home","rev_id":2}]}`,
+			[]string{messages.BatchOpOk}, ""},
+		{"failed operation", 409,
+			`{"committed":false,"dry_run":false,"results":[{"status":"rolled_back"},{"status":"failed","error":{"code":"page_exists","message":"Page exists"}},{"status":"skipped"}]}`,
+			[]string{messages.BatchOpRolledBack, messages.BatchOpFailed, messages.BatchOpSkipped}, "page_exists"},
+		{"problem details", 400,
+			`{"title":"Bad Request","status":400,"code":"invalid_request"}`,
+			nil, "invalid_request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "POST" || r.URL.Path != "/api/v1/batch" {
+					t.Errorf("got %s %s", r.Method, r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+			c := NewClient(srv.URL+"/api/v1", nil)
+			reply, err := c.Batch(context.Background(), messages.BatchRequest{})
+			if ErrorCode(err) != tt.code || (tt.code == "" && err != nil) {
+				t.Fatalf("got error %v, want code %q", err, tt.code)
+			}
+			if tt.statuses == nil {
+				if reply != nil {
+					t.Errorf("got reply %+v", reply)
+				}
+				return
+			}
+			if len(reply.Results) != len(tt.statuses) {
+				t.Fatalf("got %d results, want %d", len(reply.Results), len(tt.statuses))
+			}
+			for i, result := range reply.Results {
+				if result.Status != tt.statuses[i] {
+					t.Errorf("result %d: got status %q, want %q", i, result.Status, tt.statuses[i])
+				}
+			}
+		})
+	}
+}
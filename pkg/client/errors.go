@@ -0,0 +1,53 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// APIError is returned when the API replies with an unexpected status code.
+// Code and Message are taken from the problem details sent by the API.
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	// Stable error code, e.g. "page_not_found"
+	Code    string
+	Message string
+	// The reply, for endpoints that send more than problem details
+	body []byte
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, e.Message)
+}
+
+// Returns the status code of an APIError, or 0 if err is not one
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// Returns the error code sent by the API, or an empty string
+func ErrorCode(err error) string {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return ""
+}
+
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict
+}
@@ -0,0 +1,44 @@
+package messages
+
+import "github.com/dev-mackan/gowiki/pkg/models"
+
+type Empty struct{}
+
+type RenderReply struct {
+	Html string `json:"html"`
+	// The headings of the rendered markdown, for a table of contents
+	Toc []models.Heading `json:"toc"`
+}
+
+// Wraps every successful v2 reply
+type Envelope[T any] struct {
+	Data T `json:"data"`
+}
+
+const (
+	BatchOpOk         = "ok"
+	BatchOpFailed     = "failed"
+	BatchOpRolledBack = "rolled_back"
+	BatchOpSkipped    = "skipped"
+)
+
+type BatchReply struct {
+	// False for dry runs and failed batches
+	Committed bool            `json:"committed"`
+	DryRun    bool            `json:"dry_run"`
+	Results   []BatchOpResult `json:"results"`
+}
+
+// The result of one operation, in the order of the request
+type BatchOpResult struct {
+	Status string `json:"status"`
+	// Only set for operations that succeeded
+	*models.BatchResult
+	// Only set for the failing operation
+	Error *BatchOpError `json:"error,omitempty"`
+}
+
+type BatchOpError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,84 @@
+package messages
+
+import "github.com/dev-mackan/gowiki/pkg/models"
+
+type BundleRequest struct {
+	PageId      uint   `json:"page_id,omitempty"`
+	PageTitle   string `json:"page_title"`
+	TextContent string `json:"text_content,omitempty"`
+}
+
+type UpdateBundleRequest struct {
+	PageId      uint   `json:"page_id,omitempty"`
+	PageTitle   string `json:"page_title"`
+	TextContent string `json:"text_content,omitempty"`
+}
+
+type UpdatePageContentRequest struct {
+	PageId      uint   `json:"page_id,omitempty"`
+	TextContent string `json:"text_content,omitempty"`
+}
+
+type UpdatePageTitleRequest struct {
+	PageId    uint   `json:"page_id,omitempty"`
+	PageTitle string `json:"page_title"`
+	// Moves the subpages along, e.g. Team/Service to Ops/Service
+	MoveSubpages bool `json:"move_subpages,omitempty"`
+}
+
+type NewBundleRequest struct {
+	PageTitle   string `json:"page_title"`
+	TextContent string `json:"text_content,omitempty"`
+}
+
+type DeletePageRequest struct {
+	PageId uint `json:"page_id"`
+}
+
+type RenderRequest struct {
+	TextContent string `json:"text_content"`
+}
+
+type SaveDraftRequest struct {
+	BaseRev     uint   `json:"base_rev"`
+	TextContent string `json:"text_content"`
+}
+
+// Header identifying the owner of drafts, e.g. a web session
+const DraftOwnerHeader = "X-Gowiki-Draft-Owner"
+
+type CreatePageRequest struct {
+	Title       string `json:"title"`
+	TextContent string `json:"text_content"`
+}
+
+// Fields left out of the request are not changed
+type PatchPageRequest struct {
+	Title       *string `json:"title,omitempty"`
+	TextContent *string `json:"text_content,omitempty"`
+	// Moves the subpages along when the title changes
+	MoveSubpages bool `json:"move_subpages,omitempty"`
+}
+
+type NewRevisionRequest struct {
+	TextContent string `json:"text_content"`
+}
+
+// Replaces a section of the latest revision. With base_rev set the edit
+// fails if the page has changed since that revision.
+type UpdateSectionRequest struct {
+	TextContent string `json:"text_content"`
+	BaseRev     uint   `json:"base_rev,omitempty"`
+}
+
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+type BatchRequest struct {
+	// Run the operations without saving them
+	DryRun     bool             `json:"dry_run"`
+	Operations []models.BatchOp `json:"operations"`
+}
@@ -6,8 +6,11 @@ import (
 )
 
 type Page struct {
-	PageId    uint      `json:"page_id"`
-	Title     string    `json:"title"`
+	PageId uint `json:"page_id"`
+	// The full title, including the namespace prefix
+	Title string `json:"title"`
+	// Empty for the main namespace
+	Namespace string    `json:"namespace"`
 	LatestRev uint      `json:"latest_rev"`
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -16,6 +19,39 @@ func (p *Page) DisplayTitle() string {
 	return strings.ReplaceAll(p.Title, "_", " ")
 }
 
+// Returns the title without the namespace prefix
+func (p *Page) Name() string {
+	if p.Namespace == "" {
+		return p.Title
+	}
+	return strings.TrimPrefix(p.Title, p.Namespace+":")
+}
+
+// Returns the title of the parent page, or "" for top level pages.
+// Subpages are separated from their parent by "/", e.g. Team/Service.
+func (p *Page) ParentTitle() string {
+	i := strings.LastIndex(p.Title, "/")
+	if i < 0 {
+		return ""
+	}
+	return p.Title[:i]
+}
+
+// A new title for a page, see the subpages of RepoService.UpdatePageTitle
+type PageRename struct {
+	PageId    uint
+	Namespace string
+	Title     string
+}
+
+// A namespace groups pages by the prefix of their titles, e.g. Template:
+type Namespace struct {
+	// Empty for the main namespace
+	Name string `json:"name"`
+	// Only some tokens may edit the pages of a protected namespace
+	Protected bool `json:"protected"`
+}
+
 type Revision struct {
 	RevId     uint      `json:"rev_id"`
 	PageId    uint      `json:"page_id"`
@@ -29,6 +65,14 @@ type Text struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type Draft struct {
+	Owner     string    `json:"owner"`
+	PageId    uint      `json:"page_id"`
+	BaseRev   uint      `json:"base_rev"`
+	Content   string    `json:"content"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type PageCategory struct {
 	CatId     uint      `json:"cat_id"`
 	CatTitle  string    `json:"cat_title"`
@@ -40,9 +84,114 @@ type PageBundle struct {
 	Page     *Page     `json:"page"`
 	Revision *Revision `json:"revision"`
 	Text     *Text     `json:"text"`
+	// The YAML front matter of the text, e.g. owner, status and tags
+	Properties map[string]any `json:"properties"`
+}
+
+// Matches pages by a property in the front matter of their latest
+// revision, e.g. owner:alice. Every element of a list is matched, and
+// nested properties by their path, e.g. contact.email.
+type PropertyFilter struct {
+	Name string
+	// nil matches any value
+	Value *string
+}
+
+// A heading of a rendered page
+type Heading struct {
+	// 1 to 6, for # to ######
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	// The id of the heading, link to it with #anchor
+	Anchor string `json:"anchor"`
+}
+
+// A part of a page that can be edited on its own: a heading with
+// everything below it up to the next heading of the same or a higher
+// level. Section 0 is the text before the first heading.
+type Section struct {
+	Index int `json:"index"`
+	// 0 for section 0
+	Level   int    `json:"level"`
+	Heading string `json:"heading"`
+	// The headings above it and its own, e.g. ["Install", "Linux"]
+	Path   []string `json:"path"`
+	Anchor string   `json:"anchor"`
+	// Left out when sections are listed
+	TextContent string `json:"text_content,omitempty"`
+	// The revision the section was read from
+	RevId uint `json:"rev_id"`
+}
+
+// Page content with its template calls expanded
+type Expansion struct {
+	TextContent string `json:"text_content"`
+	// The templates used, including those called by other templates
+	Templates []*Page `json:"templates"`
+	// The titles of called templates that do not exist
+	Missing []string `json:"missing"`
 }
 
 type RevisionBundle struct {
 	Revision Revision `json:"revision"`
 	Text     Text     `json:"text"`
 }
+
+type PageChangeKind string
+
+const (
+	PageCreated PageChangeKind = "created"
+	PageEdited  PageChangeKind = "edited"
+	PageRenamed PageChangeKind = "renamed"
+	PageDeleted PageChangeKind = "deleted"
+)
+
+// Published by the repo service after a page was changed
+type PageChange struct {
+	Kind   PageChangeKind `json:"kind"`
+	PageId uint           `json:"page_id"`
+	Title  string         `json:"title"`
+	// The latest revision after the change, 0 for deleted pages
+	RevId uint      `json:"rev_id"`
+	At    time.Time `json:"at"`
+}
+
+type BatchOpKind string
+
+const (
+	BatchCreate        BatchOpKind = "create"
+	BatchUpdateContent BatchOpKind = "update_content"
+	BatchRename        BatchOpKind = "rename"
+	BatchDelete        BatchOpKind = "delete"
+)
+
+// One operation of a batch. Create uses Title and Content, update_content
+// PageId and Content, rename PageId and Title and delete PageId.
+type BatchOp struct {
+	Op      BatchOpKind `json:"op"`
+	PageId  uint        `json:"page_id,omitempty"`
+	Title   string      `json:"title,omitempty"`
+	Content string      `json:"text_content,omitempty"`
+	// Parsed from the title by the repo service
+	Namespace string `json:"-"`
+}
+
+// The page an operation of a batch changed, as it was after the operation
+type BatchResult struct {
+	PageId uint   `json:"page_id"`
+	Title  string `json:"title"`
+	// The latest revision, 0 for deleted pages
+	RevId uint `json:"rev_id,omitempty"`
+}
+
+// A response kept so a retried request with the same Idempotency-Key
+// gets it again instead of running twice
+type StoredResponse struct {
+	Key string
+	// Identifies the request the key was first used for
+	Fingerprint string
+	Status      int
+	Header      map[string][]string
+	Body        []byte
+	CreatedAt   time.Time
+}
@@ -2,16 +2,57 @@ package utils
 
 import (
 	"fmt"
-	"regexp"
+	"os"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
 )
 
+// Normalises a title to NFC and replaces whitespace with underscores.
+// Control and format characters are dropped, anything else is kept
+// so that reserved characters can be rejected rather than lost.
+// Subpages are separated by "/", so "Team / Service/" becomes
+// "Team/Service".
 func SanitizeTitle(title string) string {
-	sanitized := strings.ReplaceAll(title, " ", "_")
-	re := regexp.MustCompile(`[^a-zA-Z0-9_]`)
-	sanitized = re.ReplaceAllString(sanitized, "")
-	return sanitized
+	title = norm.NFC.String(strings.TrimSpace(title))
+	var b strings.Builder
+	for _, r := range title {
+		switch {
+		case unicode.IsSpace(r):
+			b.WriteRune('_')
+		case r == utf8.RuneError, unicode.IsControl(r), unicode.Is(unicode.Cf, r):
+			continue
+		default:
+			b.WriteRune(r)
+		}
+	}
+	segments := strings.Split(b.String(), "/")
+	kept := segments[:0]
+	for i, seg := range segments {
+		// NOTE: Only the underscores next to a slash are trimmed
+		if i > 0 {
+			seg = strings.TrimLeft(seg, "_")
+		}
+		if i < len(segments)-1 {
+			seg = strings.TrimRight(seg, "_")
+		}
+		if seg != "" {
+			kept = append(kept, seg)
+		}
+	}
+	return strings.Join(kept, "/")
+}
+
+// Returns the key titles are compared by: the NFC normalised Unicode case
+// folding of the title, so "Größe" and "GRÖSSE" are the same page
+func TitleKey(title string) string {
+	// NOTE: A Caser keeps state, so one is made per call
+	return norm.NFC.String(cases.Fold().String(norm.NFC.String(title)))
 }
 
 func ParseUintFromStr(uintStr string) (uint, error) {
@@ -27,3 +68,55 @@ func ParseUintFromStr(uintStr string) (uint, error) {
 
 	return uint(paramU64), nil
 }
+
+// Reads a comma separated list from the environment
+func EnvList(key string) []string {
+	val := os.Getenv(key)
+	if val == "" {
+		return nil
+	}
+	var list []string
+	for _, item := range strings.Split(val, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// Reads a float from the environment, falling back to def if unset or invalid
+func EnvFloat(key string, def float64) float64 {
+	val, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return def
+	}
+	return val
+}
+
+// Reads an int from the environment, falling back to def if unset or invalid
+func EnvInt(key string, def int) int {
+	val, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return val
+}
+
+// Reads a duration such as "24h" from the environment, falling back to def if unset or invalid
+func EnvDuration(key string, def time.Duration) time.Duration {
+	val, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return val
+}
+
+// Reads a string from the environment, falling back to def if unset
+func EnvString(key string, def string) string {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	return val
+}
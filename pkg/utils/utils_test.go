@@ -0,0 +1,56 @@
+package utils
+
+import "testing"
+
+func TestSanitizeTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Home", "Home"},
+		{"  Getting started  ", "Getting_started"},
+		{"Team / Service/", "Team/Service"},
+		{"Team//Service", "Team/Service"},
+		{"/Home/", "Home"},
+		{"a\tb\nc", "a_b_c"},
+		{"__Home__", "__Home__"},
+		// NOTE: "e" followed by a combining acute accent
+		{"Cafe\u0301", "Caf\u00e9"},
+		{"Zero\u200bwidth", "Zerowidth"},
+		{"Bell\x07", "Bell"},
+		{"Invalid\xff", "Invalid"},
+		{"Größe", "Größe"},
+		{"日本語 ページ", "日本語_ページ"},
+		{"Reserved#?", "Reserved#?"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := SanitizeTitle(tt.title); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTitleKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"Home", "home", true},
+		{"Größe", "GRÖSSE", true},
+		{"Cafe\u0301", "Caf\u00e9", true},
+		{"ΣΊΣΥΦΟΣ", "σίσυφος", true},
+		{"Team/Service", "team/service", true},
+		{"Home", "Homes", false},
+		{"Cafe", "Caf\u00e9", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"="+tt.b, func(t *testing.T) {
+			if same := TitleKey(tt.a) == TitleKey(tt.b); same != tt.same {
+				t.Errorf("got %q and %q", TitleKey(tt.a), TitleKey(tt.b))
+			}
+		})
+	}
+}
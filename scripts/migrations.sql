@@ -1,4 +1,8 @@
+DROP TABLE IF EXISTS IdempotencyKey;
+DROP TABLE IF EXISTS PageProperty;
+DROP TABLE IF EXISTS TemplateCall;
 
+DROP TABLE IF EXISTS Draft;
 DROP TABLE IF EXISTS Text;
 DROP TABLE IF EXISTS Revision;
 DROP TABLE IF EXISTS Page;
@@ -21,10 +25,58 @@ CREATE TABLE Revision (
 CREATE TABLE Page (
     page_id INTEGER PRIMARY KEY AUTOINCREMENT,
     title TEXT NOT NULL,
+    -- NFC normalised case folding of the title, see utils.TitleKey
+    title_key TEXT NOT NULL,
+    -- Empty for the main namespace
+    namespace TEXT NOT NULL DEFAULT '',
     latest_rev INTEGER NOT NULL DEFAULT 0,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (latest_rev) REFERENCES Revision(rev_id) ON DELETE CASCADE
 );
 -- "case insensitive" index
-CREATE UNIQUE INDEX page_title_upper ON Page (UPPER(title));
+CREATE UNIQUE INDEX page_title_key ON Page (title_key);
+CREATE INDEX page_namespace ON Page (namespace, title);
 
+-- The front matter of the latest revision of each page, one row per value,
+-- see frontmatter.Flatten
+CREATE TABLE PageProperty (
+    page_id INTEGER NOT NULL,
+    name TEXT NOT NULL COLLATE NOCASE,
+    value TEXT NOT NULL COLLATE NOCASE,
+    FOREIGN KEY (page_id) REFERENCES Page(page_id) ON DELETE CASCADE
+);
+CREATE INDEX page_property_name_value ON PageProperty (name, value);
+CREATE INDEX page_property_page_id ON PageProperty (page_id);
+
+-- The template calls of the latest revision of each page, by the name
+-- written in the call, see transclusion.Calls
+CREATE TABLE TemplateCall (
+    page_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    FOREIGN KEY (page_id) REFERENCES Page(page_id) ON DELETE CASCADE
+);
+CREATE INDEX template_call_name ON TemplateCall (name);
+CREATE INDEX template_call_page_id ON TemplateCall (page_id);
+
+-- Unpublished edits, one per owner and page revision they are based on
+CREATE TABLE Draft (
+    owner TEXT NOT NULL,
+    page_id INTEGER NOT NULL,
+    base_rev INTEGER NOT NULL,
+    content TEXT,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (owner, page_id, base_rev),
+    FOREIGN KEY (page_id) REFERENCES Page(page_id) ON DELETE CASCADE
+);
+
+-- Responses to requests with an Idempotency-Key, replayed on retries
+CREATE TABLE IdempotencyKey (
+    idem_key TEXT PRIMARY KEY,
+    fingerprint TEXT NOT NULL,
+    status INTEGER NOT NULL,
+    header TEXT NOT NULL,
+    body BLOB NOT NULL,
+    -- Unix seconds
+    created_at INTEGER NOT NULL
+);
+CREATE INDEX idempotency_key_created_at ON IdempotencyKey (created_at);
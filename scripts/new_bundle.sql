@@ -8,7 +8,7 @@ INSERT INTO Text (content) VALUES ('This is the initial content of the page.');
 -- Note: You will use this directly in the insert statements without using variables
 
 -- Step 3: Insert into the Page table
-INSERT INTO Page (title, latest_rev) VALUES ('My_First_Page', 0);
+INSERT INTO Page (title, title_key, latest_rev) VALUES ('My_First_Page', 'my_first_page', 0);
 
 -- Step 4: Insert the initial revision into the Revision table
 INSERT INTO Revision (page_id, text_id) 
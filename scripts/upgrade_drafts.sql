@@ -0,0 +1,18 @@
+-- Upgrades a database created before the editor saved drafts, or before
+-- drafts were kept under a hash of the session id. Drafts saved under the
+-- session id itself can no longer be found, so they are dropped.
+
+BEGIN TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS Draft (
+    owner TEXT NOT NULL,
+    page_id INTEGER NOT NULL,
+    base_rev INTEGER NOT NULL,
+    content TEXT,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (owner, page_id, base_rev),
+    FOREIGN KEY (page_id) REFERENCES Page(page_id) ON DELETE CASCADE
+);
+DELETE FROM Draft;
+
+COMMIT;
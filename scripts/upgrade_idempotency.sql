@@ -0,0 +1,17 @@
+-- Upgrades a database created before requests could carry an
+-- Idempotency-Key. Without it every such request fails.
+
+BEGIN TRANSACTION;
+
+CREATE TABLE IdempotencyKey (
+    idem_key TEXT PRIMARY KEY,
+    fingerprint TEXT NOT NULL,
+    status INTEGER NOT NULL,
+    header TEXT NOT NULL,
+    body BLOB NOT NULL,
+    -- Unix seconds
+    created_at INTEGER NOT NULL
+);
+CREATE INDEX idempotency_key_created_at ON IdempotencyKey (created_at);
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Upgrades a database created before pages had namespaces. Existing pages
+-- are put in the main namespace, renaming a page moves it to the namespace
+-- of its new title.
+
+BEGIN TRANSACTION;
+
+ALTER TABLE Page ADD COLUMN namespace TEXT NOT NULL DEFAULT '';
+CREATE INDEX page_namespace ON Page (namespace, title);
+
+COMMIT;
@@ -0,0 +1,16 @@
+-- Upgrades a database created before pages had properties. Run
+-- gowiki_api -reindex afterwards to index the front matter of the
+-- existing pages, they are not found by their properties until then.
+
+BEGIN TRANSACTION;
+
+CREATE TABLE PageProperty (
+    page_id INTEGER NOT NULL,
+    name TEXT NOT NULL COLLATE NOCASE,
+    value TEXT NOT NULL COLLATE NOCASE,
+    FOREIGN KEY (page_id) REFERENCES Page(page_id) ON DELETE CASCADE
+);
+CREATE INDEX page_property_name_value ON PageProperty (name, value);
+CREATE INDEX page_property_page_id ON PageProperty (page_id);
+
+COMMIT;
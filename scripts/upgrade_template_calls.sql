@@ -0,0 +1,15 @@
+-- Upgrades a database created before template calls were indexed. Run
+-- gowiki_api -reindex afterwards to index the calls of the existing
+-- pages, they are not listed as template users until then.
+
+BEGIN TRANSACTION;
+
+CREATE TABLE TemplateCall (
+    page_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    FOREIGN KEY (page_id) REFERENCES Page(page_id) ON DELETE CASCADE
+);
+CREATE INDEX template_call_name ON TemplateCall (name);
+CREATE INDEX template_call_page_id ON TemplateCall (page_id);
+
+COMMIT;
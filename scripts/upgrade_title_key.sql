@@ -0,0 +1,12 @@
+-- Upgrades a database created before titles were compared by their case
+-- folded key. Titles used to be limited to [a-zA-Z0-9_], for which the
+-- key is the lowercase title.
+
+BEGIN TRANSACTION;
+
+ALTER TABLE Page ADD COLUMN title_key TEXT NOT NULL DEFAULT '';
+UPDATE Page SET title_key = LOWER(title);
+DROP INDEX IF EXISTS page_title_upper;
+CREATE UNIQUE INDEX page_title_key ON Page (title_key);
+
+COMMIT;
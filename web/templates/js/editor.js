@@ -0,0 +1,64 @@
+// Live preview and draft autosave for the markdown editor.
+// The markdown is rendered by the server so the preview matches the page.
+(function () {
+    const editor = document.getElementById("text_content");
+    const preview = document.getElementById("preview");
+    if (!editor || !preview) {
+        return;
+    }
+    const token = editor.form.querySelector("input[name=csrf_token]").value;
+    // NOTE: Only existing pages have drafts
+    const pageId = editor.dataset.pageId;
+    const baseRev = Number(editor.dataset.baseRev);
+    let previewTimer = null;
+    let draftTimer = null;
+
+    function render() {
+        fetch("/preview", {
+            method: "POST",
+            headers: { "Content-Type": "text/markdown", "X-CSRF-Token": token },
+            body: editor.value,
+        })
+            .then((resp) => (resp.ok ? resp.text() : Promise.reject(resp.status)))
+            .then((html) => { preview.innerHTML = html; })
+            .catch((err) => console.error("preview failed:", err));
+    }
+
+    function saveDraft() {
+        fetch("/drafts/" + pageId, {
+            method: "PUT",
+            headers: { "Content-Type": "application/json", "X-CSRF-Token": token },
+            body: JSON.stringify({ base_rev: baseRev, text_content: editor.value }),
+        }).catch((err) => console.error("autosave failed:", err));
+    }
+
+    function discardDraft() {
+        return fetch("/drafts/" + pageId, {
+            method: "DELETE",
+            headers: { "X-CSRF-Token": token },
+        }).catch((err) => console.error("discarding draft failed:", err));
+    }
+
+    editor.addEventListener("input", () => {
+        clearTimeout(previewTimer);
+        previewTimer = setTimeout(render, 300);
+        if (pageId) {
+            clearTimeout(draftTimer);
+            draftTimer = setTimeout(saveDraft, 2000);
+        }
+    });
+
+    const prompt = document.getElementById("draft_prompt");
+    if (prompt) {
+        document.getElementById("draft_resume").addEventListener("click", () => {
+            editor.value = document.getElementById("draft_content").value;
+            prompt.remove();
+            render();
+            saveDraft();
+        });
+        document.getElementById("draft_discard").addEventListener("click", () => {
+            discardDraft().then(() => prompt.remove());
+        });
+    }
+    render();
+})();